@@ -1,10 +1,140 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/mrizkimaulidan/storial/internal/backfill"
+	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/database/migration"
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/story"
 	"github.com/mrizkimaulidan/storial/internal/server"
+	"github.com/mrizkimaulidan/storial/internal/service/file"
+	"github.com/mrizkimaulidan/storial/pkg/time"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		runBackfill(os.Args[2:])
+		return
+	}
+
 	s := server.NewServer()
 	s.Run()
 }
+
+// Handle the migrate subcommand.
+// Usage: migrate up|down|status|baseline [version]
+func migrate(args []string) {
+	if len(args) < 1 || len(args) > 2 || (len(args) == 2 && args[0] != "baseline") {
+		log.Fatalln("usage: migrate up|down|status|baseline [version]")
+	}
+
+	db := database.NewDatabase().Connect()
+	defer db.Close()
+
+	ctx := context.Background()
+	m := migration.New(db)
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mg := range applied {
+			log.Printf("applied %d_%s\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			log.Fatalln("error applying migrations", err)
+		}
+
+		if len(applied) == 0 {
+			log.Println("nothing to migrate")
+		}
+	case "down":
+		mg, err := m.Down(ctx)
+		if err != nil {
+			log.Fatalln("error rolling back migration", err)
+		}
+
+		log.Printf("rolled back %d_%s\n", mg.Version, mg.Name)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			log.Fatalln("error getting migration status", err)
+		}
+
+		for _, s := range statuses {
+			status := "pending"
+			if s.Applied {
+				status = fmt.Sprintf("applied at %s", time.UnixToTime(s.AppliedAt).Format("2006-01-02 15:04:05"))
+			}
+
+			fmt.Printf("%06d_%s\t%s\n", s.Version, s.Name, status)
+		}
+	case "baseline":
+		version := migration.LegacyVersion
+		if len(args) == 2 {
+			v, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				log.Fatalln("invalid baseline version", args[1])
+			}
+
+			version = v
+		}
+
+		marked, err := m.Baseline(ctx, version)
+		for _, mg := range marked {
+			log.Printf("marked %d_%s as applied\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			log.Fatalln("error baselining migrations", err)
+		}
+
+		if len(marked) == 0 {
+			log.Println("nothing to baseline")
+		}
+	default:
+		log.Fatalln("usage: migrate up|down|status|baseline [version]")
+	}
+}
+
+// Handle the backfill subcommand, refusing to run on the outdated schema.
+// Usage: backfill metrics|covers
+func runBackfill(args []string) {
+	if len(args) != 1 {
+		log.Fatalln("usage: backfill metrics|covers")
+	}
+
+	db := database.NewDatabase().Open()
+	defer db.Close()
+
+	ctx := context.Background()
+	b := backfill.New(chapter.NewRepository(), story.NewRepository(), file.NewService(), db)
+
+	switch args[0] {
+	case "metrics":
+		changed, err := b.ChapterMetrics(ctx)
+		if err != nil {
+			log.Fatalln("error backfilling chapter metrics", err)
+		}
+
+		log.Printf("recomputed metrics of %d chapters\n", changed)
+	case "covers":
+		converted, err := b.CoverVariants(ctx)
+		if err != nil {
+			log.Fatalln("error backfilling cover variants", err)
+		}
+
+		log.Printf("generated variants of %d covers\n", converted)
+	default:
+		log.Fatalln("usage: backfill metrics|covers")
+	}
+}
@@ -0,0 +1,178 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"path"
+
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	"github.com/mrizkimaulidan/storial/internal/service/file"
+	storyservice "github.com/mrizkimaulidan/storial/internal/service/story"
+	fileexception "github.com/mrizkimaulidan/storial/pkg/exception/file"
+	"github.com/mrizkimaulidan/storial/pkg/imaging"
+)
+
+// Number of chapters recomputed on each transaction.
+var BATCH_SIZE uint64 = 200
+
+// Backfill recompute the derived columns of the existing rows in batches,
+// so the long running backfill does not hold single large transaction.
+type Backfill struct {
+	chapterRepository chapter.ChapterRepository
+	storyRepository   story.StoryRepository
+	fileService       file.FileService
+	db                *sql.DB
+}
+
+// Recompute word counts and reading time of every chapter.
+// Returning how many chapters have been changed.
+func (b *Backfill) ChapterMetrics(ctx context.Context) (uint64, error) {
+	var changed, afterID uint64
+	for {
+		n, lastID, err := b.chapterMetricsBatch(ctx, afterID)
+		if err != nil {
+			return changed, err
+		}
+
+		changed += n
+		if lastID == afterID {
+			return changed, nil
+		}
+
+		afterID = lastID
+	}
+}
+
+// Recompute single batch of chapters after the given ID inside single transaction.
+// Returning how many chapters have been changed and ID of the last chapter.
+func (b *Backfill) chapterMetricsBatch(ctx context.Context, afterID uint64) (uint64, uint64, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return 0, afterID, err
+	}
+
+	chapters, err := b.chapterRepository.FindBodiesAfterID(ctx, tx, afterID, BATCH_SIZE)
+	if err != nil {
+		tx.Rollback()
+		return 0, afterID, err
+	}
+
+	var changed uint64
+	lastID := afterID
+	for _, c := range *chapters {
+		lastID = c.Id
+
+		previous := c
+		c.CalculateMetrics()
+		if c.WordCounts == previous.WordCounts && c.ReadingTime == previous.ReadingTime {
+			continue
+		}
+
+		err = b.chapterRepository.UpdateMetrics(ctx, tx, c)
+		if err != nil {
+			tx.Rollback()
+			return 0, afterID, err
+		}
+
+		changed++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, afterID, err
+	}
+
+	return changed, lastID, nil
+}
+
+// Generate the cover variants of the stories which cover was uploaded before
+// the variants, stored as cover/{filename}. The legacy file is kept.
+// Returning how many covers have been converted.
+func (b *Backfill) CoverVariants(ctx context.Context) (uint64, error) {
+	var converted, afterID uint64
+	for {
+		tx, err := b.db.Begin()
+		if err != nil {
+			return converted, err
+		}
+
+		stories, err := b.storyRepository.FindCoversAfterID(ctx, tx, afterID, BATCH_SIZE)
+		tx.Rollback()
+		if err != nil {
+			return converted, err
+		}
+
+		if len(*stories) == 0 {
+			return converted, nil
+		}
+
+		for _, s := range *stories {
+			afterID = s.Id
+
+			ok, err := b.coverVariants(ctx, s.Cover)
+			if err != nil {
+				return converted, err
+			}
+
+			if ok {
+				converted++
+			}
+		}
+	}
+}
+
+// Generate the variants of single legacy cover. The cover that already has
+// the variants is skipped, so the backfill can be run again after failure.
+// The missing or undecodable legacy file is only logged.
+func (b *Backfill) coverVariants(ctx context.Context, filename string) (bool, error) {
+	if path.Base(filename) != filename {
+		log.Println("skipping cover with invalid filename", filename)
+		return false, nil
+	}
+
+	rc, err := b.fileService.Open(ctx, path.Join(storyservice.COVER_PATH, storyservice.COVER_DEFAULT_VARIANT, filename))
+	if err == nil {
+		rc.Close()
+		return false, nil
+	}
+
+	if !errors.Is(err, fileexception.ErrFileNotFound) {
+		return false, err
+	}
+
+	rc, err = b.fileService.Open(ctx, path.Join(storyservice.COVER_PATH, filename))
+	if errors.Is(err, fileexception.ErrFileNotFound) {
+		log.Println("skipping cover without legacy file", filename)
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+	defer rc.Close()
+
+	img, _, err := imaging.Decode(rc, storyservice.COVER_MAX_SIZE)
+	if err != nil {
+		log.Println("skipping cover", filename, err)
+		return false, nil
+	}
+
+	err = storyservice.PutCoverVariants(ctx, b.fileService, img, filename)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func New(cr chapter.ChapterRepository, sr story.StoryRepository, fs file.FileService, db *sql.DB) *Backfill {
+	return &Backfill{
+		chapterRepository: cr,
+		storyRepository:   sr,
+		fileService:       fs,
+		db:                db,
+	}
+}
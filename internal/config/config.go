@@ -15,6 +15,25 @@ type Config struct {
 	DB_PASSWORD    string
 	APP_PORT       string
 	JWT_SECRET_KEY string
+	NODE_ID        string
+
+	STORAGE_DRIVER     string
+	STORAGE_LOCAL_PATH string
+	STORAGE_LOCAL_URL  string
+	STORAGE_URL_EXPIRY string
+	S3_ENDPOINT        string
+	S3_REGION          string
+	S3_BUCKET          string
+	S3_ACCESS_KEY      string
+	S3_SECRET_KEY      string
+	S3_PUBLIC_URL      string
+	S3_USE_PATH_STYLE  string
+
+	SCHEDULER_INTERVAL string
+
+	VIEW_DEDUP_WINDOW   string
+	VIEW_FLUSH_INTERVAL string
+	VIEW_BATCH_SIZE     string
 }
 
 // Get config based on .env file.
@@ -32,10 +51,39 @@ func (c *Config) GetConfig() *Config {
 	c.DB_PASSWORD = os.Getenv("DB_PASSWORD")
 	c.APP_PORT = os.Getenv("APP_PORT")
 	c.JWT_SECRET_KEY = os.Getenv("JWT_SECRET_KEY")
+	c.NODE_ID = getenv("NODE_ID", "0")
+
+	c.STORAGE_DRIVER = getenv("STORAGE_DRIVER", "local")
+	c.STORAGE_LOCAL_PATH = getenv("STORAGE_LOCAL_PATH", "public")
+	c.STORAGE_LOCAL_URL = getenv("STORAGE_LOCAL_URL", "/public")
+	c.STORAGE_URL_EXPIRY = getenv("STORAGE_URL_EXPIRY", "3600")
+	c.S3_ENDPOINT = os.Getenv("S3_ENDPOINT")
+	c.S3_REGION = getenv("S3_REGION", "us-east-1")
+	c.S3_BUCKET = os.Getenv("S3_BUCKET")
+	c.S3_ACCESS_KEY = os.Getenv("S3_ACCESS_KEY")
+	c.S3_SECRET_KEY = os.Getenv("S3_SECRET_KEY")
+	c.S3_PUBLIC_URL = os.Getenv("S3_PUBLIC_URL")
+	c.S3_USE_PATH_STYLE = getenv("S3_USE_PATH_STYLE", "true")
+
+	c.SCHEDULER_INTERVAL = getenv("SCHEDULER_INTERVAL", "60")
+
+	c.VIEW_DEDUP_WINDOW = getenv("VIEW_DEDUP_WINDOW", "1800")
+	c.VIEW_FLUSH_INTERVAL = getenv("VIEW_FLUSH_INTERVAL", "10")
+	c.VIEW_BATCH_SIZE = getenv("VIEW_BATCH_SIZE", "500")
 
 	return c
 }
 
+// Get environment variable, if empty returning the fallback value.
+func getenv(key string, fallback string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+
+	return v
+}
+
 func New() *Config {
 	return &Config{}
 }
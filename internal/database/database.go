@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -8,15 +9,29 @@ import (
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/mrizkimaulidan/storial/internal/config"
+	"github.com/mrizkimaulidan/storial/internal/database/migration"
 )
 
 type Database struct {
 	c *config.Config
 }
 
-// Opening database connection.
-// If something goes wrong, will throwing an fatal error.
+// Opening database connection and make sure the schema is up to date.
+// If something goes wrong or there is pending migration, will throwing an fatal error.
 func (d *Database) Open() *sql.DB {
+	db := d.Connect()
+
+	err := migration.New(db).Check(context.Background())
+	if err != nil {
+		log.Fatalln("error checking database schema", err)
+	}
+
+	return db
+}
+
+// Opening database connection without checking the schema version.
+// If something goes wrong, will throwing an fatal error.
+func (d *Database) Connect() *sql.DB {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		d.c.DB_USERNAME,
 		d.c.DB_PASSWORD,
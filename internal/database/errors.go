@@ -0,0 +1,18 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error number of the duplicate entry on the unique key.
+const ER_DUP_ENTRY = 1062
+
+// Checking the error is the duplicate entry on the unique key of the given name.
+func IsDuplicateEntry(err error, key string) bool {
+	var e *mysql.MySQLError
+
+	return errors.As(err, &e) && e.Number == ER_DUP_ENTRY && strings.Contains(e.Message, key)
+}
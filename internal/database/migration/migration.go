@@ -0,0 +1,394 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+// Embedded migration files. Every migration consists of a pair of files
+// named {version}_{name}.up.sql and {version}_{name}.down.sql.
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// Latest migration version covered by the former storial.sql dump.
+// Database created from the dump must be baselined to this version.
+const LegacyVersion uint64 = 5
+
+var (
+	ErrNoMigrationApplied = errors.New("no migration has been applied")
+	ErrSchemaOutdated     = errors.New("database schema is outdated")
+	ErrUnknownVersion     = errors.New("unknown migration version")
+)
+
+// Struct that represent single versioned migration.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Struct that represent migration status on database.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt uint64
+}
+
+type Migrator struct {
+	db *sql.DB
+}
+
+// Apply every pending migration in ascending version order.
+// Returning the migrations that has been applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, mg := range pending {
+		err := m.exec(ctx, mg.Up)
+		if err != nil {
+			return applied, fmt.Errorf("applying migration %d_%s: %w", mg.Version, mg.Name, err)
+		}
+
+		query := `
+			INSERT INTO schema_migrations(version, name, applied_at)
+			VALUES(?, ?, ?)
+		`
+
+		_, err = m.db.ExecContext(ctx, query, mg.Version, mg.Name, time.CurrentTimeToUnixTimestamp())
+		if err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, mg)
+	}
+
+	return applied, nil
+}
+
+// Mark every pending migration up to the given version as applied without
+// executing it, for database which schema was created outside the migrator.
+// Returning the migrations that has been marked.
+func (m *Migrator) Baseline(ctx context.Context, version uint64) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	known := false
+	for _, mg := range migrations {
+		if mg.Version == version {
+			known = true
+			break
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("%w %d", ErrUnknownVersion, version)
+	}
+
+	var marked []Migration
+	for _, mg := range pending {
+		if mg.Version > version {
+			break
+		}
+
+		query := `
+			INSERT INTO schema_migrations(version, name, applied_at)
+			VALUES(?, ?, ?)
+		`
+
+		_, err = m.db.ExecContext(ctx, query, mg.Version, mg.Name, time.CurrentTimeToUnixTimestamp())
+		if err != nil {
+			return marked, err
+		}
+
+		marked = append(marked, mg)
+	}
+
+	return marked, nil
+}
+
+// Rollback the latest applied migration.
+// If there is no migration applied, returning err no migration applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *Migration
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].Applied {
+			latest = &statuses[i].Migration
+			break
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoMigrationApplied
+	}
+
+	err = m.exec(ctx, latest.Down)
+	if err != nil {
+		return nil, fmt.Errorf("rolling back migration %d_%s: %w", latest.Version, latest.Name, err)
+	}
+
+	query := `
+		DELETE
+		FROM
+			schema_migrations
+		WHERE
+			version = ?
+	`
+
+	_, err = m.db.ExecContext(ctx, query, latest.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+// Get status of every known migration.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, mg := range migrations {
+		appliedAt, ok := applied[mg.Version]
+		statuses = append(statuses, Status{
+			Migration: mg,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Get migrations that not applied yet to database.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, s := range statuses {
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Checking the database schema is up to date with the embedded migrations.
+// If there is pending migration, returning err schema outdated.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s), run `migrate up`", ErrSchemaOutdated, len(pending))
+	}
+
+	return nil
+}
+
+// Get applied migration versions with the time it was applied.
+// The bookkeeping table will be created if not exists.
+func (m *Migrator) applied(ctx context.Context) (map[uint64]uint64, error) {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint(20) unsigned NOT NULL,
+			name varchar(255) NOT NULL,
+			applied_at bigint(20) NOT NULL,
+			PRIMARY KEY (version)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`
+
+	_, err := m.db.ExecContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT
+		version,
+		applied_at
+	FROM
+		schema_migrations
+	`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint64]uint64)
+	for rows.Next() {
+		var version, appliedAt uint64
+		err := rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Execute every statement on migration file one by one.
+// The MySQL driver does not allow multiple statements on single query.
+func (m *Migrator) exec(ctx context.Context, content string) error {
+	for _, stmt := range split(content) {
+		_, err := m.db.ExecContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Split migration file into statements. Statement must be ended with
+// semicolon at the end of line.
+func split(content string) []string {
+	var statements []string
+	var b strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(b.String()), ";"))
+			b.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// Load embedded migration files sorted by version.
+func load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[uint64]*Migration)
+	for _, e := range entries {
+		name := e.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration filename %s", name)
+		}
+
+		version, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s", name)
+		}
+
+		content, err := fs.ReadFile(files, path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		mg, ok := migrations[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: parts[1]}
+			migrations[version] = mg
+		}
+
+		if mg.Name != parts[1] {
+			return nil, fmt.Errorf("duplicate migration version %d", version)
+		}
+
+		if direction == "up" {
+			mg.Up = string(content)
+		} else {
+			mg.Down = string(content)
+		}
+	}
+
+	var sorted []Migration
+	for _, mg := range migrations {
+		if mg.Up == "" || mg.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down file", mg.Version, mg.Name)
+		}
+
+		sorted = append(sorted, *mg)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return sorted, nil
+}
+
+func New(db *sql.DB) *Migrator {
+	return &Migrator{
+		db: db,
+	}
+}
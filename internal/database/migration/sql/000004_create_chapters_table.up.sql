@@ -0,0 +1,16 @@
+CREATE TABLE `chapters` (
+  `id` bigint(20) unsigned NOT NULL,
+  `story_id` bigint(20) unsigned NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `slug` varchar(255) NOT NULL,
+  `body` longtext NOT NULL,
+  `author_comment` text DEFAULT NULL,
+  `word_counts` bigint(20) NOT NULL,
+  `reading_time` varchar(255) NOT NULL,
+  `is_published` tinyint(1) NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  `updated_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `story_id_index` (`story_id`),
+  CONSTRAINT `chapters_ibfk_1` FOREIGN KEY (`story_id`) REFERENCES `stories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+CREATE TABLE `chapter_likes` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `chapter_id` bigint(20) unsigned NOT NULL,
+  `user_id` bigint(20) unsigned NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `story_id_index` (`chapter_id`),
+  KEY `user_id_index` (`user_id`),
+  CONSTRAINT `chapter_likes_ibfk_1` FOREIGN KEY (`chapter_id`) REFERENCES `chapters` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `chapter_likes_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
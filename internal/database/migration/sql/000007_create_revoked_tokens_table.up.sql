@@ -0,0 +1,6 @@
+CREATE TABLE `revoked_tokens` (
+  `jti` varchar(64) NOT NULL,
+  `expires_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`jti`),
+  KEY `expires_at_index` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
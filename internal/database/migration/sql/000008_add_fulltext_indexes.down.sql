@@ -0,0 +1,2 @@
+ALTER TABLE `chapters` DROP INDEX `chapters_fulltext`;
+ALTER TABLE `stories` DROP INDEX `stories_fulltext`;
@@ -0,0 +1,2 @@
+ALTER TABLE `stories` ADD FULLTEXT KEY `stories_fulltext` (`title`, `description`);
+ALTER TABLE `chapters` ADD FULLTEXT KEY `chapters_fulltext` (`title`, `body`);
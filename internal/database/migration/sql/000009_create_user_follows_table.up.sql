@@ -0,0 +1,9 @@
+CREATE TABLE `user_follows` (
+  `follower_id` bigint(20) unsigned NOT NULL,
+  `followee_id` bigint(20) unsigned NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`follower_id`, `followee_id`),
+  KEY `followee_id_index` (`followee_id`),
+  CONSTRAINT `user_follows_ibfk_1` FOREIGN KEY (`follower_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `user_follows_ibfk_2` FOREIGN KEY (`followee_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE `chapter_likes` DROP INDEX `chapter_id_user_id_unique`;
@@ -0,0 +1,9 @@
+-- remove duplicate likes, keeping the oldest one
+DELETE newer
+FROM
+	chapter_likes newer
+	INNER JOIN chapter_likes older ON newer.chapter_id = older.chapter_id
+	AND newer.user_id = older.user_id
+	AND newer.id > older.id;
+
+ALTER TABLE `chapter_likes` ADD UNIQUE KEY `chapter_id_user_id_unique` (`chapter_id`, `user_id`);
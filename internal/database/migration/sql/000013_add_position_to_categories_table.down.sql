@@ -0,0 +1,2 @@
+ALTER TABLE `categories` DROP INDEX `categories_slug_unique`;
+ALTER TABLE `categories` DROP COLUMN `position`;
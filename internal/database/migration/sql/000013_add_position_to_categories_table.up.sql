@@ -0,0 +1,20 @@
+ALTER TABLE `categories` ADD COLUMN `position` int(10) unsigned NOT NULL DEFAULT 0 AFTER `slug`;
+
+-- keep the current alphabetical order as the initial position
+UPDATE
+	categories
+	INNER JOIN (
+		SELECT
+			a.id,
+			COUNT(*) AS pos
+		FROM
+			categories a
+			INNER JOIN categories b ON b.name < a.name
+			OR (b.name = a.name AND b.id <= a.id)
+		GROUP BY
+			a.id
+	) ranked ON ranked.id = categories.id
+SET
+	categories.position = ranked.pos;
+
+ALTER TABLE `categories` ADD UNIQUE KEY `categories_slug_unique` (`slug`);
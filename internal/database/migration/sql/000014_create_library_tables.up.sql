@@ -0,0 +1,23 @@
+CREATE TABLE `story_bookmarks` (
+  `user_id` bigint(20) unsigned NOT NULL,
+  `story_id` bigint(20) unsigned NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`user_id`, `story_id`),
+  KEY `story_id_index` (`story_id`),
+  CONSTRAINT `story_bookmarks_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `story_bookmarks_ibfk_2` FOREIGN KEY (`story_id`) REFERENCES `stories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `reading_progress` (
+  `user_id` bigint(20) unsigned NOT NULL,
+  `story_id` bigint(20) unsigned NOT NULL,
+  `chapter_id` bigint(20) unsigned NOT NULL,
+  `scroll_position` tinyint(3) unsigned NOT NULL DEFAULT 0,
+  `updated_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`user_id`, `story_id`),
+  KEY `story_id_index` (`story_id`),
+  KEY `chapter_id_index` (`chapter_id`),
+  CONSTRAINT `reading_progress_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `reading_progress_ibfk_2` FOREIGN KEY (`story_id`) REFERENCES `stories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `reading_progress_ibfk_3` FOREIGN KEY (`chapter_id`) REFERENCES `chapters` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE `chapters` DROP INDEX `story_id_position_index`;
+ALTER TABLE `chapters` DROP COLUMN `position`;
@@ -0,0 +1,20 @@
+ALTER TABLE `chapters` ADD COLUMN `position` int(10) unsigned NOT NULL DEFAULT 0;
+
+-- number the existing chapters of every story from the oldest chapter
+UPDATE
+	chapters
+	INNER JOIN (
+		SELECT
+			a.id,
+			COUNT(*) AS pos
+		FROM
+			chapters a
+			INNER JOIN chapters b ON b.story_id = a.story_id
+			AND (b.created_at < a.created_at OR (b.created_at = a.created_at AND b.id <= a.id))
+		GROUP BY
+			a.id
+	) ranked ON ranked.id = chapters.id
+SET
+	chapters.position = ranked.pos;
+
+ALTER TABLE `chapters` ADD KEY `story_id_position_index` (`story_id`, `position`);
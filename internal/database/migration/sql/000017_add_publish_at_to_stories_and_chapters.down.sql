@@ -0,0 +1,5 @@
+ALTER TABLE `chapters` DROP INDEX `is_published_publish_at_index`;
+ALTER TABLE `chapters` DROP COLUMN `publish_at`;
+
+ALTER TABLE `stories` DROP INDEX `is_published_publish_at_index`;
+ALTER TABLE `stories` DROP COLUMN `publish_at`;
@@ -0,0 +1,5 @@
+ALTER TABLE `stories` ADD COLUMN `publish_at` bigint(20) DEFAULT NULL;
+ALTER TABLE `stories` ADD KEY `is_published_publish_at_index` (`is_published`, `publish_at`);
+
+ALTER TABLE `chapters` ADD COLUMN `publish_at` bigint(20) DEFAULT NULL;
+ALTER TABLE `chapters` ADD KEY `is_published_publish_at_index` (`is_published`, `publish_at`);
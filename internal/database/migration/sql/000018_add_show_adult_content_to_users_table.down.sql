@@ -0,0 +1 @@
+ALTER TABLE `users` DROP COLUMN `show_adult_content`;
@@ -0,0 +1 @@
+ALTER TABLE `users` ADD COLUMN `show_adult_content` tinyint(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE `chapter_likes` DROP INDEX `created_at_index`;
+ALTER TABLE `chapter_likes` DROP COLUMN `created_at`;
@@ -0,0 +1,4 @@
+-- likes made before this migration have no known time and are left as zero
+ALTER TABLE `chapter_likes`
+  ADD COLUMN `created_at` bigint(20) NOT NULL DEFAULT 0,
+  ADD KEY `created_at_index` (`created_at`);
@@ -0,0 +1,3 @@
+DELETE FROM `chapter_views` WHERE `user_id` IS NULL;
+ALTER TABLE `chapter_views` DROP COLUMN `fingerprint`;
+ALTER TABLE `chapter_views` MODIFY `user_id` bigint(20) unsigned NOT NULL;
@@ -0,0 +1,3 @@
+-- anonymous readers are identified by the client fingerprint instead of the user
+ALTER TABLE `chapter_views` MODIFY `user_id` bigint(20) unsigned DEFAULT NULL;
+ALTER TABLE `chapter_views` ADD COLUMN `fingerprint` char(64) DEFAULT NULL AFTER `user_id`;
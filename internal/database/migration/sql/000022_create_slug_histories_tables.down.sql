@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `chapter_slug_histories`;
+DROP TABLE IF EXISTS `story_slug_histories`;
+ALTER TABLE `chapters` DROP INDEX `chapters_story_id_slug_unique`;
+ALTER TABLE `stories` DROP INDEX `stories_slug_unique`;
@@ -0,0 +1,54 @@
+-- chapters sharing the same slug on the same story are suffixed with their ID
+UPDATE
+	chapters
+	INNER JOIN (
+		SELECT
+			b.id
+		FROM
+			chapters a
+			INNER JOIN chapters b ON b.story_id = a.story_id
+			AND b.slug = a.slug
+			AND b.id > a.id
+		GROUP BY
+			b.id
+	) duplicated ON duplicated.id = chapters.id
+SET
+	chapters.slug = CONCAT(chapters.slug, '-', chapters.id);
+
+UPDATE
+	stories
+	INNER JOIN (
+		SELECT
+			b.id
+		FROM
+			stories a
+			INNER JOIN stories b ON b.slug = a.slug
+			AND b.id > a.id
+		GROUP BY
+			b.id
+	) duplicated ON duplicated.id = stories.id
+SET
+	stories.slug = CONCAT(stories.slug, '-', stories.id);
+
+ALTER TABLE `stories` ADD UNIQUE KEY `stories_slug_unique` (`slug`);
+ALTER TABLE `chapters` ADD UNIQUE KEY `chapters_story_id_slug_unique` (`story_id`, `slug`);
+
+CREATE TABLE `story_slug_histories` (
+  `slug` varchar(255) NOT NULL,
+  `story_id` bigint(20) unsigned NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`slug`),
+  KEY `story_id_index` (`story_id`),
+  CONSTRAINT `story_slug_histories_ibfk_1` FOREIGN KEY (`story_id`) REFERENCES `stories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `chapter_slug_histories` (
+  `story_id` bigint(20) unsigned NOT NULL,
+  `slug` varchar(255) NOT NULL,
+  `chapter_id` bigint(20) unsigned NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`story_id`, `slug`),
+  KEY `chapter_id_index` (`chapter_id`),
+  CONSTRAINT `chapter_slug_histories_ibfk_1` FOREIGN KEY (`story_id`) REFERENCES `stories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `chapter_slug_histories_ibfk_2` FOREIGN KEY (`chapter_id`) REFERENCES `chapters` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
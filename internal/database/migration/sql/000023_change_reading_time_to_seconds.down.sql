@@ -0,0 +1,3 @@
+ALTER TABLE `chapters` MODIFY `reading_time` varchar(255) NOT NULL;
+
+UPDATE chapters SET reading_time = CONCAT(CAST(reading_time AS UNSIGNED) DIV 60, ' Minutes');
@@ -0,0 +1,5 @@
+-- the previous "N Minutes" text is converted into seconds, run the
+-- backfill command to recompute the word counts and reading time
+UPDATE chapters SET reading_time = CAST(SUBSTRING_INDEX(reading_time, ' ', 1) AS UNSIGNED) * 60;
+
+ALTER TABLE `chapters` MODIFY `reading_time` int(10) unsigned NOT NULL DEFAULT 0;
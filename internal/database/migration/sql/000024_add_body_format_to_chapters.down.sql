@@ -0,0 +1,2 @@
+ALTER TABLE `chapters` DROP COLUMN `body_html`;
+ALTER TABLE `chapters` DROP COLUMN `body_format`;
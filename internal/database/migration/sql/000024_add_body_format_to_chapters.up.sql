@@ -0,0 +1,4 @@
+-- the rendered HTML of the existing chapters is empty, it is rendered on read
+-- until the chapter is saved again
+ALTER TABLE `chapters` ADD COLUMN `body_format` varchar(16) NOT NULL DEFAULT 'plain';
+ALTER TABLE `chapters` ADD COLUMN `body_html` longtext NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE `chapter_revisions` DROP COLUMN `body_format`;
@@ -0,0 +1,2 @@
+-- the existing revisions were written before the chapter format existed
+ALTER TABLE `chapter_revisions` ADD COLUMN `body_format` varchar(16) NOT NULL DEFAULT 'plain';
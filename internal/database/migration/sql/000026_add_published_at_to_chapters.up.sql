@@ -0,0 +1,4 @@
+-- the publish time of the existing chapters is unknown, the latest update
+-- is the closest to it
+ALTER TABLE `chapters` ADD COLUMN `published_at` bigint(20) DEFAULT NULL;
+UPDATE `chapters` SET `published_at` = `updated_at` WHERE `is_published` = 1;
@@ -0,0 +1,11 @@
+package entity
+
+// Struct that represent story bookmark entity.
+// Progress is nil when the user has not read the story yet.
+type Bookmark struct {
+	UserID    uint64
+	StoryID   uint64
+	Story     Story
+	Progress  *ReadingProgress
+	CreatedAt uint64
+}
@@ -1,8 +1,17 @@
 package entity
 
+import "github.com/mrizkimaulidan/storial/pkg/slug"
+
 // Struct that represent category entity.
 type Category struct {
-	Id   uint64
-	Slug string
-	Name string
+	Id       uint64
+	Slug     string
+	Name     string
+	Position uint64
+}
+
+// Convert to slug format.
+// Letters are transliterated and anything else is replaced with single dash.
+func (c *Category) ToSlug(str string) string {
+	return slug.Make(str)
 }
@@ -1,11 +1,18 @@
 package entity
 
 import (
-	"fmt"
-	"math/rand"
-	"strconv"
-	"strings"
-	"time"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/pkg/markdown"
+	"github.com/mrizkimaulidan/storial/pkg/slug"
+	"github.com/mrizkimaulidan/storial/pkg/snowflake"
+	"github.com/mrizkimaulidan/storial/pkg/textmetrics"
+)
+
+// Formats of the chapter body source.
+var (
+	BODY_FORMAT_PLAIN    = "plain"
+	BODY_FORMAT_MARKDOWN = "markdown"
 )
 
 // Struct that represent chapter entity.
@@ -19,34 +26,71 @@ type Chapter struct {
 	Body          string
 	AuthorComment string
 	WordCounts    uint64
-	ReadingTime   string
+	ReadingTime   uint64
 	IsPublished   bool
 	CreatedAt     uint64
 	UpdatedAt     uint64
+	Position      uint64
+
+	// Scheduled publish time, the chapter is published by the scheduler once due.
+	PublishAt sql.NullInt64
+
+	// Time the chapter was first published.
+	PublishedAt sql.NullInt64
+
+	// Format of the body source and the sanitized HTML rendered from it.
+	BodyFormat string
+	BodyHTML   string
 }
 
-// Generate random ID.
-func (s *Chapter) GenerateID() int {
-	rand.Seed(time.Now().UnixNano() / 1000000)
+// Generate unique time ordered ID.
+func (s *Chapter) GenerateID() uint64 {
+	return snowflake.Generate()
+}
 
-	return rand.Intn(999999)
+// Record the publish time when the chapter is published for the first time,
+// otherwise the previous publish time is kept.
+func (s *Chapter) RecordPublished(previous sql.NullInt64, now uint64) {
+	s.PublishedAt = previous
+	if s.IsPublished && !previous.Valid {
+		s.PublishedAt = sql.NullInt64{Int64: int64(now), Valid: true}
+	}
 }
 
-// Convert to slug format.
+// Convert to slug format, falling back to "chapter" when nothing is left.
 func (s *Chapter) ToSlug(str string) string {
-	l := strings.ToLower(str)
+	sl := slug.Make(str)
+	if sl == "" {
+		return "chapter"
+	}
 
-	return strings.ReplaceAll(l, " ", "-")
+	return sl
 }
 
-// Counting how many characters on body property.
-func (s *Chapter) CountChars() int {
-	return len([]rune(s.Body))
+// Calculating word counts and reading time in seconds of the body.
+func (s *Chapter) CalculateMetrics() {
+	m := textmetrics.Analyze(s.Body)
+
+	s.WordCounts = m.WordCounts()
+	s.ReadingTime = m.ReadingTime()
+}
+
+// Rendering the body into sanitized HTML based on the body format.
+func (s *Chapter) RenderBody() {
+	if s.BodyFormat == BODY_FORMAT_MARKDOWN {
+		s.BodyHTML = markdown.Render(s.Body)
+		return
+	}
+
+	s.BodyHTML = markdown.RenderText(s.Body)
 }
 
-// Calculating how many reading time needed on chapter.
-func (s *Chapter) CalculateReadingTime() string {
-	result := float64((s.WordCounts / 200))
+// Get the rendered HTML of the body, the chapter saved before the HTML
+// is stored is rendered on the fly.
+func (s *Chapter) HTML() string {
+	if s.BodyHTML == "" {
+		s.RenderBody()
+	}
 
-	return fmt.Sprintf("%s Minutes", strconv.Itoa(int(result)))
+	return s.BodyHTML
 }
@@ -0,0 +1,18 @@
+package entity
+
+// Struct that represent chapter revision entity.
+// Revision is the snapshot of the chapter before it was edited.
+type ChapterRevision struct {
+	Id            uint64
+	ChapterID     uint64
+	Title         string
+	Body          string
+	BodyFormat    string
+	AuthorComment string
+	CreatedAt     uint64
+}
+
+// Checking the revision has the same content with the chapter.
+func (cr *ChapterRevision) IsSameContent(c Chapter) bool {
+	return cr.Title == c.Title && cr.Body == c.Body && cr.BodyFormat == c.BodyFormat && cr.AuthorComment == c.AuthorComment
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "database/sql"
+
+// Struct that represent a chapter being served to a reader.
+// Anonymous reader has no user, identified by the client fingerprint instead.
+type ChapterView struct {
+	Id          uint64
+	ChapterID   uint64
+	StoryID     uint64
+	UserID      sql.NullInt64
+	Fingerprint sql.NullString
+	CreatedAt   uint64
+}
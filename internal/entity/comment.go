@@ -0,0 +1,21 @@
+package entity
+
+import "database/sql"
+
+// Struct that represent chapter comment entity.
+// Comment without parent is a top level comment, otherwise it is a reply.
+type Comment struct {
+	Id        uint64
+	ChapterID uint64
+	UserID    uint64
+	User      User
+	ParentID  sql.NullInt64
+	Body      string
+	CreatedAt uint64
+	UpdatedAt uint64
+}
+
+// Checking the comment is a reply of another comment.
+func (c *Comment) IsReply() bool {
+	return c.ParentID.Valid
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "math"
+
+// Struct that represent user reading progress on a story.
+// The scroll position is percentage of the current chapter.
+type ReadingProgress struct {
+	UserID         uint64
+	StoryID        uint64
+	ChapterID      uint64
+	Chapter        Chapter
+	ScrollPosition uint64
+	UpdatedAt      uint64
+
+	// Word counts of the published chapters before the current chapter
+	// and word counts of every published chapter on the story.
+	PreviousWordCounts uint64
+	TotalWordCounts    uint64
+}
+
+// Calculating how many percent of the story has been read
+// based on the chapters word counts, rounded to one decimal.
+func (rp *ReadingProgress) Percentage() float64 {
+	if rp.TotalWordCounts == 0 {
+		return 0
+	}
+
+	read := float64(rp.PreviousWordCounts) + float64(rp.Chapter.WordCounts)*float64(rp.ScrollPosition)/100
+	percentage := math.Round(read/float64(rp.TotalWordCounts)*1000) / 10
+
+	return math.Min(percentage, 100)
+}
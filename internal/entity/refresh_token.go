@@ -0,0 +1,24 @@
+package entity
+
+import "database/sql"
+
+// Struct that represent refresh token entity.
+type RefreshToken struct {
+	Id         uint64
+	UserID     uint64
+	TokenHash  string
+	ExpiresAt  uint64
+	RevokedAt  sql.NullInt64
+	ReplacedBy sql.NullInt64
+	CreatedAt  uint64
+}
+
+// Checking the refresh token has been revoked.
+func (rt *RefreshToken) IsRevoked() bool {
+	return rt.RevokedAt.Valid
+}
+
+// Checking the refresh token has been expired based on n unix timestamp.
+func (rt *RefreshToken) IsExpired(n uint64) bool {
+	return rt.ExpiresAt <= n
+}
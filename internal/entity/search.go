@@ -0,0 +1,12 @@
+package entity
+
+// Struct that represent single search hit.
+// The chapter is empty when the hit type is story.
+type SearchResult struct {
+	Type    string
+	Story   Story
+	Chapter Chapter
+	User    User
+	Text    string
+	Score   float64
+}
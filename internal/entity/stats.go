@@ -0,0 +1,34 @@
+package entity
+
+// Struct that represent aggregated statistics of a story.
+// Average completion is percentage of the story read by the readers.
+type StoryStats struct {
+	Story             Story
+	Views             uint64
+	UniqueReaders     uint64
+	Likes             uint64
+	Comments          uint64
+	AverageCompletion float64
+}
+
+// Struct that represent aggregated statistics of a chapter.
+// Average completion is percentage of the chapter read by the readers
+// who have reached the chapter.
+type ChapterStats struct {
+	Chapter           Chapter
+	Views             uint64
+	UniqueReaders     uint64
+	Likes             uint64
+	Comments          uint64
+	AverageCompletion float64
+}
+
+// Struct that represent likes received on a single day.
+// Day is the unix timestamp of the start of the day in UTC,
+// ChapterID is zero when the likes are counted for the whole story.
+type DailyLikes struct {
+	StoryID   uint64
+	ChapterID uint64
+	Day       uint64
+	Likes     uint64
+}
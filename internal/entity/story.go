@@ -1,10 +1,11 @@
 package entity
 
 import (
+	"database/sql"
 	"fmt"
-	"math/rand"
-	"strings"
-	"time"
+
+	"github.com/mrizkimaulidan/storial/pkg/slug"
+	"github.com/mrizkimaulidan/storial/pkg/snowflake"
 )
 
 // Struct that represent story entity.
@@ -22,23 +23,33 @@ type Story struct {
 	IsPublished bool
 	CreatedAt   uint64
 	UpdatedAt   uint64
-}
 
-// Generate random ID.
-func (s *Story) GenerateID() int {
-	rand.Seed(time.Now().UnixNano() / 1000000)
+	// Scheduled publish time, the story is published by the scheduler once due.
+	PublishAt sql.NullInt64
+
+	// Latest chapter updated_at, only loaded when filtering by modified chapter.
+	LatestChapterUpdatedAt uint64
+
+	// Latest chapter published_at, only loaded when filtering by published chapter.
+	LatestChapterPublishedAt uint64
+}
 
-	return rand.Intn(999999)
+// Generate unique time ordered ID.
+func (s *Story) GenerateID() uint64 {
+	return snowflake.Generate()
 }
 
-// Convert to slug format.
+// Convert to slug format, falling back to "story" when nothing is left.
 func (s *Story) ToSlug(str string) string {
-	l := strings.ToLower(str)
+	sl := slug.Make(str)
+	if sl == "" {
+		return "story"
+	}
 
-	return strings.ReplaceAll(l, " ", "-")
+	return sl
 }
 
-// Get cover path.
-func (s *Story) CoverPath() string {
-	return fmt.Sprintf("/public/cover/%s", s.Cover)
+// Get cover storage key of the variant.
+func (s *Story) CoverPath(variant string) string {
+	return fmt.Sprintf("cover/%s/%s", variant, s.Cover)
 }
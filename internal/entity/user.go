@@ -2,8 +2,9 @@ package entity
 
 import (
 	"database/sql"
-	"math/rand"
 	"time"
+
+	"github.com/mrizkimaulidan/storial/pkg/snowflake"
 )
 
 // Struct that represent user entity.
@@ -14,6 +15,7 @@ type User struct {
 	Email       string
 	Password    string
 	Sex         uint8
+	Role        string
 	Bio         *sql.NullString
 	DateOfBirth *sql.NullInt64
 	PhoneNumber *sql.NullString
@@ -21,13 +23,61 @@ type User struct {
 	Instagram   *sql.NullString
 	Facebook    *sql.NullString
 	CreatedAt   uint64
+
+	// Opted in to see adult content, only applied to the verified adult.
+	ShowAdultContent bool
+}
+
+var (
+	ROLE_READER    = "reader"
+	ROLE_WRITER    = "writer"
+	ROLE_MODERATOR = "moderator"
+	ROLE_ADMIN     = "admin"
+
+	// Ordered from the least to the most privileged role.
+	ROLES = []string{ROLE_READER, ROLE_WRITER, ROLE_MODERATOR, ROLE_ADMIN}
+
+	// Minimum age in years to see adult content.
+	ADULT_AGE = 18
+)
+
+// Get level of the role, more privileged role has higher level.
+// Unknown role has zero level.
+func RoleLevel(role string) int {
+	for i, r := range ROLES {
+		if r == role {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Checking the user role is at least the given role.
+func (u *User) HasRole(role string) bool {
+	return RoleLevel(u.Role) >= RoleLevel(role)
 }
 
-// Generate random ID.
-func (u *User) GenerateID() int {
-	rand.Seed(time.Now().UnixNano() / 1000000)
+// Checking the user has reached the adult age on the given time.
+// User without date of birth is not verified as adult.
+func (u *User) IsAdultAt(t time.Time) bool {
+	if u.DateOfBirth == nil || !u.DateOfBirth.Valid {
+		return false
+	}
+
+	dateOfBirth := time.UnixMilli(u.DateOfBirth.Int64).UTC()
+
+	return !dateOfBirth.AddDate(ADULT_AGE, 0, 0).After(t)
+}
+
+// Checking the user is verified adult and opted in to see adult content.
+func (u *User) CanSeeAdultContent(t time.Time) bool {
+	return u.ShowAdultContent && u.IsAdultAt(t)
+}
 
-	return rand.Intn(999999)
+// Generate unique time ordered ID.
+func (u *User) GenerateID() uint64 {
+	return snowflake.Generate()
 }
 
 // Get gender name by int.
@@ -0,0 +1,11 @@
+package entity
+
+// Struct that represent user follow entity.
+// The follower is the user who follows the followee.
+type UserFollow struct {
+	FollowerID uint64
+	Follower   User
+	FolloweeID uint64
+	Followee   User
+	CreatedAt  uint64
+}
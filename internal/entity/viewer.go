@@ -0,0 +1,56 @@
+package entity
+
+// Struct that represent the user reading the stories and chapters.
+// Reader only see the published content, the author also see the own
+// drafts and the moderator see everything.
+// Adult story is only visible to the viewer allowed to see adult content.
+type Viewer struct {
+	UserID uint64
+	Role   string
+	Adult  bool
+}
+
+// Checking the viewer can see every draft.
+func (v Viewer) CanSeeDrafts() bool {
+	return RoleLevel(v.Role) >= RoleLevel(ROLE_MODERATOR)
+}
+
+// Checking the viewer can see adult content.
+func (v Viewer) CanSeeAdult() bool {
+	return v.Adult || v.CanSeeDrafts()
+}
+
+// Checking the story is visible to the viewer.
+func (v Viewer) CanSeeStory(s Story) bool {
+	return s.IsPublished || s.UserID == v.UserID || v.CanSeeDrafts()
+}
+
+// Checking the chapter is visible to the viewer.
+// Chapter of the draft story is not visible even it's published.
+func (v Viewer) CanSeeChapter(c Chapter, s Story) bool {
+	return (s.IsPublished && c.IsPublished) || s.UserID == v.UserID || v.CanSeeDrafts()
+}
+
+// Checking the viewer is allowed to read the story when it's adult story.
+// The author can always read the own story.
+func (v Viewer) CanReadAdultStory(s Story) bool {
+	return !s.IsAdult || s.UserID == v.UserID || v.CanSeeAdult()
+}
+
+// Get SQL condition of the visible stories, the query must select from stories table.
+func (v Viewer) StoryCondition() (string, []any) {
+	if v.CanSeeDrafts() {
+		return "1 = 1", nil
+	}
+
+	return "(stories.user_id = ? OR (stories.is_published = 1 AND (stories.is_adult = 0 OR ?)))", []any{v.UserID, v.CanSeeAdult()}
+}
+
+// Get SQL condition of the visible chapters, the query must join chapters and stories table.
+func (v Viewer) ChapterCondition() (string, []any) {
+	if v.CanSeeDrafts() {
+		return "1 = 1", nil
+	}
+
+	return "(stories.user_id = ? OR (stories.is_published = 1 AND chapters.is_published = 1 AND (stories.is_adult = 0 OR ?)))", []any{v.UserID, v.CanSeeAdult()}
+}
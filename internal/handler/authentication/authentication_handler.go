@@ -9,6 +9,7 @@ import (
 	model "github.com/mrizkimaulidan/storial/internal/model/authentication"
 	"github.com/mrizkimaulidan/storial/internal/service/authentication"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/authentication"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
 	"github.com/mrizkimaulidan/storial/pkg/response"
 )
 
@@ -54,6 +55,46 @@ func (ah *authenticationHandler) Login() http.Handler {
 	})
 }
 
+func (ah *authenticationHandler) Refresh() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := model.RefreshTokenRequest{
+			RefreshToken: r.PostFormValue("refreshToken"),
+		}
+
+		refreshResponse, err := ah.authenticationService.Refresh(r.Context(), request)
+		if err != nil {
+			ah.handleErr(err).JSON(w)
+			return
+		}
+
+		ah.response.SetCode(http.StatusOK).SetMessage("OK").SetData(refreshResponse).JSON(w)
+	})
+}
+
+func (ah *authenticationHandler) Logout() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.LogoutRequest{
+			UserID:       user.Id,
+			TokenID:      user.ID,
+			RefreshToken: r.PostFormValue("refreshToken"),
+		}
+
+		if user.ExpiresAt != nil {
+			request.TokenExpiresAt = uint64(user.ExpiresAt.UnixMilli())
+		}
+
+		logoutResponse, err := ah.authenticationService.Logout(r.Context(), request)
+		if err != nil {
+			ah.handleErr(err).JSON(w)
+			return
+		}
+
+		ah.response.SetCode(http.StatusOK).SetMessage("OK").SetData(logoutResponse).JSON(w)
+	})
+}
+
 func (ah *authenticationHandler) handleErr(err error) *response.Response {
 	switch {
 	case errors.As(err, &validation.Errors{}):
@@ -66,6 +107,14 @@ func (ah *authenticationHandler) handleErr(err error) *response.Response {
 		return ah.response.Error(err).SetCode(http.StatusBadRequest)
 	case errors.Is(err, exception.ErrPasswordAreWrong):
 		return ah.response.Error(err).SetCode(http.StatusUnauthorized)
+	case errors.Is(err, exception.ErrRefreshTokenInvalid):
+		return ah.response.Error(err).SetCode(http.StatusUnauthorized)
+	case errors.Is(err, exception.ErrRefreshTokenExpired):
+		return ah.response.Error(err).SetCode(http.StatusUnauthorized)
+	case errors.Is(err, exception.ErrRefreshTokenRevoked):
+		return ah.response.Error(err).SetCode(http.StatusUnauthorized)
+	case errors.Is(err, exception.ErrUserNotFound):
+		return ah.response.Error(err).SetCode(http.StatusNotFound)
 	}
 
 	log.Println("[ERROR]", err)
@@ -7,4 +7,6 @@ import (
 type AuthenticationHandler interface {
 	Register() http.Handler
 	Login() http.Handler
+	Refresh() http.Handler
+	Logout() http.Handler
 }
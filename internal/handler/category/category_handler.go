@@ -1,10 +1,15 @@
 package category
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	model "github.com/mrizkimaulidan/storial/internal/model/category"
 	"github.com/mrizkimaulidan/storial/internal/service/category"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/category"
 	"github.com/mrizkimaulidan/storial/pkg/response"
 )
 
@@ -25,9 +30,96 @@ func (ch *categoryHandler) GetAllCategory() http.Handler {
 	})
 }
 
+func (ch *categoryHandler) Store() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := model.CreateCategoryRequest{
+			Name: r.PostFormValue("name"),
+		}
+
+		categoryResponse, err := ch.categoryService.Create(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusCreated).SetMessage("OK").SetData(categoryResponse).JSON(w)
+	})
+}
+
+func (ch *categoryHandler) Update() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		request := model.UpdateCategoryRequest{
+			CategoryID: vars["categoryId"],
+			Name:       r.PostFormValue("name"),
+		}
+
+		categoryResponse, err := ch.categoryService.Rename(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(categoryResponse).JSON(w)
+	})
+}
+
+func (ch *categoryHandler) Delete() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		request := model.DeleteCategoryRequest{
+			CategoryID: vars["categoryId"],
+			ReassignTo: r.URL.Query().Get("reassignTo"),
+		}
+
+		deletedResponse, err := ch.categoryService.Delete(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(deletedResponse).JSON(w)
+	})
+}
+
+func (ch *categoryHandler) Reorder() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			ch.response.Error(err).SetCode(http.StatusBadRequest).JSON(w)
+			return
+		}
+
+		request := model.ReorderCategoryRequest{
+			CategoryIDs: r.PostForm["categoryIds"],
+		}
+
+		categoriesResponse, err := ch.categoryService.Reorder(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(categoriesResponse).JSON(w)
+	})
+}
+
 func (ch *categoryHandler) handleErr(err error) *response.Response {
-	switch err {
-	//
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCategoryNotFound):
+		return ch.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrCategorySlugNotAvailable):
+		return ch.response.Error(err).SetCode(http.StatusConflict)
+	case errors.Is(err, exception.ErrCategoryHasStories):
+		return ch.response.Error(err).SetCode(http.StatusConflict)
+	case errors.Is(err, exception.ErrInvalidReassignCategory):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrInvalidCategoryOrder):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
 	}
 
 	log.Println("[ERROR]", err)
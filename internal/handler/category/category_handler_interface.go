@@ -4,4 +4,8 @@ import "net/http"
 
 type CategoryHandler interface {
 	GetAllCategory() http.Handler
+	Store() http.Handler
+	Update() http.Handler
+	Delete() http.Handler
+	Reorder() http.Handler
 }
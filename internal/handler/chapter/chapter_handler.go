@@ -1,22 +1,30 @@
 package chapter
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	model "github.com/mrizkimaulidan/storial/internal/model/chapter"
 	"github.com/mrizkimaulidan/storial/internal/service/chapter"
+	"github.com/mrizkimaulidan/storial/internal/tracker"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
 	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
 	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 	"github.com/mrizkimaulidan/storial/pkg/response"
+	"github.com/mrizkimaulidan/storial/pkg/time"
 )
 
 type chapterHandler struct {
 	chapterService chapter.ChapterService
+	viewTracker    *tracker.ViewTracker
 	response       *response.Response
 }
 
@@ -27,7 +35,24 @@ func (ch *chapterHandler) LikeChapter() http.Handler {
 		chapterID := vars["chapterId"]
 		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
 
-		likedResponse, err := ch.chapterService.LikeChapter(r.Context(), storyID, chapterID, user.Id)
+		likedResponse, err := ch.chapterService.LikeChapter(r.Context(), user.Viewer(), storyID, chapterID)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(likedResponse).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) UnlikeChapter() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		storyID := vars["storyId"]
+		chapterID := vars["chapterId"]
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		likedResponse, err := ch.chapterService.UnlikeChapter(r.Context(), user.Viewer(), storyID, chapterID)
 		if err != nil {
 			ch.handleErr(err).JSON(w)
 			return
@@ -47,8 +72,10 @@ func (ch *chapterHandler) AddChapter() http.Handler {
 			StorySlug:     vars["storySlug"],
 			Title:         r.PostFormValue("title"),
 			Body:          r.PostFormValue("body"),
+			Format:        r.PostFormValue("format"),
 			AuthorComment: r.PostFormValue("authorComment"),
 			IsPublished:   r.PostFormValue("isPublished"),
+			PublishAt:     r.PostFormValue("publishAt"),
 		}
 
 		chapterResponse, err := ch.chapterService.AddChapter(r.Context(), request)
@@ -72,8 +99,10 @@ func (ch *chapterHandler) EditChapter() http.Handler {
 			ChapterSlug:   vars["chapterSlug"],
 			Title:         r.PostFormValue("title"),
 			Body:          r.PostFormValue("body"),
+			Format:        r.PostFormValue("format"),
 			AuthorComment: r.PostFormValue("authorComment"),
 			IsPublished:   r.PostFormValue("isPublished"),
+			PublishAt:     r.PostFormValue("publishAt"),
 		}
 
 		chapterResponse, err := ch.chapterService.EditChapter(r.Context(), request)
@@ -109,7 +138,115 @@ func (ch *chapterHandler) GetChapter() http.Handler {
 		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
 		chapterSlug := vars["chapterSlug"]
 
-		chapterResponse, err := ch.chapterService.GetChapterByStorySlugAndChapterSlug(r.Context(), user.Id, storySlug, chapterSlug)
+		chapterResponse, err := ch.chapterService.GetChapterByStorySlugAndChapterSlug(r.Context(), user.Viewer(), storySlug, chapterSlug, r.URL.Query().Get("format"))
+
+		// the story or the chapter has been renamed
+		var moved *storyexception.SlugMovedError
+		if errors.As(err, &moved) {
+			response.Redirect(w, r, fmt.Sprintf("/api/v1/book/%s/%s", url.PathEscape(moved.StorySlug), url.PathEscape(moved.ChapterSlug)))
+			return
+		}
+
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		// the writer reading their own chapter is not counted as a view
+		if chapterResponse.User.Id != user.Id {
+			ch.viewTracker.Track(chapterView(r, user.Id, chapterResponse))
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(chapterResponse).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) ReorderChapters() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			ch.response.Error(err).SetCode(http.StatusBadRequest).JSON(w)
+			return
+		}
+
+		request := model.ReorderChapterRequest{
+			UserID:     user.Id,
+			StoryID:    vars["storyId"],
+			ChapterIDs: r.PostForm["chapterIds"],
+		}
+
+		chaptersResponse, err := ch.chapterService.ReorderChapters(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(chaptersResponse).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) GetRevisions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		revisionsResponse, meta, err := ch.chapterService.GetRevisions(r.Context(), user.Id, vars["storyId"], vars["chapterId"], pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(revisionsResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) GetRevision() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		revisionResponse, err := ch.chapterService.GetRevision(r.Context(), user.Id, vars["storyId"], vars["chapterId"], vars["revisionId"])
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(revisionResponse).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) DiffRevisions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.DiffRevisionRequest{
+			UserID:    user.Id,
+			StoryID:   vars["storyId"],
+			ChapterID: vars["chapterId"],
+			From:      r.URL.Query().Get("from"),
+			To:        r.URL.Query().Get("to"),
+		}
+
+		diffResponse, err := ch.chapterService.DiffRevisions(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(diffResponse).JSON(w)
+	})
+}
+
+func (ch *chapterHandler) RestoreRevision() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		chapterResponse, err := ch.chapterService.RestoreRevision(r.Context(), user.Id, vars["storyId"], vars["chapterId"], vars["revisionId"])
 		if err != nil {
 			ch.handleErr(err).JSON(w)
 			return
@@ -123,14 +260,15 @@ func (ch *chapterHandler) GetChapters() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		storyID := vars["storyId"]
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
 
-		chaptersResponse, err := ch.chapterService.GetAllChapterByStoryID(r.Context(), storyID)
+		chaptersResponse, meta, err := ch.chapterService.GetAllChapterByStoryID(r.Context(), user.Viewer(), storyID, pagination.NewRequest(r.URL.Query()))
 		if err != nil {
 			ch.handleErr(err).JSON(w)
 			return
 		}
 
-		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(chaptersResponse).JSON(w)
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(chaptersResponse).SetMeta(meta).JSON(w)
 	})
 }
 
@@ -142,17 +280,48 @@ func (ch *chapterHandler) handleErr(err error) *response.Response {
 		return ch.response.Error(err).SetCode(http.StatusNotFound)
 	case errors.Is(err, storyexception.ErrStoryNotFound):
 		return ch.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, storyexception.ErrAdultContentRestricted):
+		return ch.response.Error(err).SetCode(http.StatusForbidden)
 	case errors.Is(err, exception.ErrCannotLikeYourOwnChapter):
 		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrInvalidChapterOrder):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrRevisionNotFound):
+		return ch.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrInvalidFormat):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrSlugTaken):
+		return ch.response.Error(err).SetCode(http.StatusConflict)
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
 	}
 
 	log.Println("[ERROR]", err)
 	return ch.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
 }
 
-func NewHandler(chapterService chapter.ChapterService) ChapterHandler {
+// Build the view of the served chapter, anonymous reader is identified
+// by the client fingerprint.
+func chapterView(r *http.Request, userID uint64, c *model.ChapterResponseByStorySlugAndChapterSlug) entity.ChapterView {
+	v := entity.ChapterView{
+		ChapterID: c.Id,
+		StoryID:   c.StoryID,
+		CreatedAt: time.CurrentTimeToUnixTimestamp(),
+	}
+
+	if userID != 0 {
+		v.UserID = sql.NullInt64{Int64: int64(userID), Valid: true}
+	} else {
+		v.Fingerprint = sql.NullString{String: tracker.Fingerprint(r), Valid: true}
+	}
+
+	return v
+}
+
+func NewHandler(chapterService chapter.ChapterService, viewTracker *tracker.ViewTracker) ChapterHandler {
 	return &chapterHandler{
 		chapterService: chapterService,
+		viewTracker:    viewTracker,
 		response:       new(response.Response),
 	}
 }
@@ -8,5 +8,11 @@ type ChapterHandler interface {
 	GetChapter() http.Handler
 	DeleteChapter() http.Handler
 	GetChapters() http.Handler
+	ReorderChapters() http.Handler
+	GetRevisions() http.Handler
+	GetRevision() http.Handler
+	DiffRevisions() http.Handler
+	RestoreRevision() http.Handler
 	LikeChapter() http.Handler
+	UnlikeChapter() http.Handler
 }
@@ -0,0 +1,157 @@
+package comment
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	model "github.com/mrizkimaulidan/storial/internal/model/comment"
+	"github.com/mrizkimaulidan/storial/internal/service/comment"
+	chapterexception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/comment"
+	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/response"
+)
+
+type commentHandler struct {
+	commentService comment.CommentService
+	response       *response.Response
+}
+
+func (ch *commentHandler) GetComments() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		commentsResponse, meta, err := ch.commentService.GetAllCommentByChapterID(r.Context(), user.Viewer(), vars["storyId"], vars["chapterId"], pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(commentsResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+func (ch *commentHandler) AddComment() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.CreateCommentRequest{
+			UserID:    user.Id,
+			StoryID:   vars["storyId"],
+			ChapterID: vars["chapterId"],
+			Body:      r.PostFormValue("body"),
+		}
+
+		commentResponse, err := ch.commentService.AddComment(r.Context(), user.Viewer(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusCreated).SetMessage("OK").SetData(commentResponse).JSON(w)
+	})
+}
+
+func (ch *commentHandler) ReplyComment() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.CreateCommentRequest{
+			UserID:    user.Id,
+			StoryID:   vars["storyId"],
+			ChapterID: vars["chapterId"],
+			ParentID:  vars["commentId"],
+			Body:      r.PostFormValue("body"),
+		}
+
+		commentResponse, err := ch.commentService.AddComment(r.Context(), user.Viewer(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusCreated).SetMessage("OK").SetData(commentResponse).JSON(w)
+	})
+}
+
+func (ch *commentHandler) EditComment() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.UpdateCommentRequest{
+			UserID:    user.Id,
+			StoryID:   vars["storyId"],
+			ChapterID: vars["chapterId"],
+			CommentID: vars["commentId"],
+			Body:      r.PostFormValue("body"),
+		}
+
+		commentResponse, err := ch.commentService.EditComment(r.Context(), user.Viewer(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(commentResponse).JSON(w)
+	})
+}
+
+func (ch *commentHandler) DeleteComment() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.DeleteCommentRequest{
+			UserID:    user.Id,
+			StoryID:   vars["storyId"],
+			ChapterID: vars["chapterId"],
+			CommentID: vars["commentId"],
+		}
+
+		commentResponse, err := ch.commentService.RemoveComment(r.Context(), request)
+		if err != nil {
+			ch.handleErr(err).JSON(w)
+			return
+		}
+
+		ch.response.SetCode(http.StatusOK).SetMessage("OK").SetData(commentResponse).JSON(w)
+	})
+}
+
+func (ch *commentHandler) handleErr(err error) *response.Response {
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, chapterexception.ErrChapterNotFound):
+		return ch.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, storyexception.ErrAdultContentRestricted):
+		return ch.response.Error(err).SetCode(http.StatusForbidden)
+	case errors.Is(err, exception.ErrCommentNotFound):
+		return ch.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrNotAllowedToEdit):
+		return ch.response.Error(err).SetCode(http.StatusForbidden)
+	case errors.Is(err, exception.ErrNotAllowedToDelete):
+		return ch.response.Error(err).SetCode(http.StatusForbidden)
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return ch.response.Error(err).SetCode(http.StatusBadRequest)
+	}
+
+	log.Println("[ERROR]", err)
+	return ch.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
+}
+
+func NewHandler(commentService comment.CommentService) CommentHandler {
+	return &commentHandler{
+		commentService: commentService,
+		response:       new(response.Response),
+	}
+}
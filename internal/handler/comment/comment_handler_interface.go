@@ -0,0 +1,11 @@
+package comment
+
+import "net/http"
+
+type CommentHandler interface {
+	GetComments() http.Handler
+	AddComment() http.Handler
+	ReplyComment() http.Handler
+	EditComment() http.Handler
+	DeleteComment() http.Handler
+}
@@ -0,0 +1,131 @@
+package library
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	model "github.com/mrizkimaulidan/storial/internal/model/library"
+	"github.com/mrizkimaulidan/storial/internal/service/library"
+	chapterexception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/library"
+	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/response"
+)
+
+type libraryHandler struct {
+	libraryService library.LibraryService
+	response       *response.Response
+}
+
+func (lh *libraryHandler) GetLibrary() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		libraryResponse, meta, err := lh.libraryService.GetLibrary(r.Context(), user.Id, pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			lh.handleErr(err).JSON(w)
+			return
+		}
+
+		lh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(libraryResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+func (lh *libraryHandler) Bookmark() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		bookmarkResponse, err := lh.libraryService.Bookmark(r.Context(), user.Viewer(), vars["storyId"])
+		if err != nil {
+			lh.handleErr(err).JSON(w)
+			return
+		}
+
+		lh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(bookmarkResponse).JSON(w)
+	})
+}
+
+func (lh *libraryHandler) Unbookmark() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		bookmarkResponse, err := lh.libraryService.Unbookmark(r.Context(), user.Id, vars["storyId"])
+		if err != nil {
+			lh.handleErr(err).JSON(w)
+			return
+		}
+
+		lh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(bookmarkResponse).JSON(w)
+	})
+}
+
+func (lh *libraryHandler) GetProgress() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		progressResponse, err := lh.libraryService.GetProgress(r.Context(), user.Viewer(), vars["storyId"])
+		if err != nil {
+			lh.handleErr(err).JSON(w)
+			return
+		}
+
+		lh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(progressResponse).JSON(w)
+	})
+}
+
+func (lh *libraryHandler) UpdateProgress() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		request := model.UpdateProgressRequest{
+			UserID:         user.Id,
+			StoryID:        vars["storyId"],
+			ChapterID:      r.PostFormValue("chapterId"),
+			ScrollPosition: r.PostFormValue("scrollPosition"),
+		}
+
+		progressResponse, err := lh.libraryService.ReportProgress(r.Context(), user.Viewer(), request)
+		if err != nil {
+			lh.handleErr(err).JSON(w)
+			return
+		}
+
+		lh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(progressResponse).JSON(w)
+	})
+}
+
+func (lh *libraryHandler) handleErr(err error) *response.Response {
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return lh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, storyexception.ErrStoryNotFound):
+		return lh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, storyexception.ErrAdultContentRestricted):
+		return lh.response.Error(err).SetCode(http.StatusForbidden)
+	case errors.Is(err, chapterexception.ErrChapterNotFound):
+		return lh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrProgressNotFound):
+		return lh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return lh.response.Error(err).SetCode(http.StatusBadRequest)
+	}
+
+	log.Println("[ERROR]", err)
+	return lh.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
+}
+
+func NewHandler(libraryService library.LibraryService) LibraryHandler {
+	return &libraryHandler{
+		libraryService: libraryService,
+		response:       new(response.Response),
+	}
+}
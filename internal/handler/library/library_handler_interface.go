@@ -0,0 +1,11 @@
+package library
+
+import "net/http"
+
+type LibraryHandler interface {
+	GetLibrary() http.Handler
+	Bookmark() http.Handler
+	Unbookmark() http.Handler
+	GetProgress() http.Handler
+	UpdateProgress() http.Handler
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	model "github.com/mrizkimaulidan/storial/internal/model/search"
+	"github.com/mrizkimaulidan/storial/internal/service/search"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/response"
+)
+
+type searchHandler struct {
+	searchService search.SearchService
+	response      *response.Response
+}
+
+func (sh *searchHandler) Search() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.SearchRequest{
+			Query:        r.URL.Query().Get("q"),
+			IncludeAdult: user.Viewer().CanSeeAdult(),
+		}
+
+		// search result is ordered by relevance, only offset pagination is supported
+		page := pagination.Request{
+			Page:  r.URL.Query().Get("page"),
+			Limit: r.URL.Query().Get("limit"),
+		}
+
+		searchResponse, meta, err := sh.searchService.Search(r.Context(), request, page)
+		if err != nil {
+			sh.handleErr(err).JSON(w)
+			return
+		}
+
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(searchResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+func (sh *searchHandler) handleErr(err error) *response.Response {
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return sh.response.Error(err).SetCode(http.StatusBadRequest)
+	}
+
+	log.Println("[ERROR]", err)
+	return sh.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
+}
+
+func NewHandler(searchService search.SearchService) SearchHandler {
+	return &searchHandler{
+		searchService: searchService,
+		response:      new(response.Response),
+	}
+}
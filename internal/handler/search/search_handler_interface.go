@@ -0,0 +1,7 @@
+package search
+
+import "net/http"
+
+type SearchHandler interface {
+	Search() http.Handler
+}
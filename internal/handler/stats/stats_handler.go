@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	model "github.com/mrizkimaulidan/storial/internal/model/stats"
+	"github.com/mrizkimaulidan/storial/internal/service/stats"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/response"
+)
+
+type statsHandler struct {
+	statsService stats.StatsService
+	response     *response.Response
+}
+
+func (sh *statsHandler) GetWriterStats() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		request := model.StatsRequest{
+			UserID: user.Id,
+			Days:   r.URL.Query().Get("days"),
+		}
+
+		statsResponse, err := sh.statsService.GetWriterStats(r.Context(), request)
+		if err != nil {
+			sh.handleErr(err).JSON(w)
+			return
+		}
+
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(statsResponse).JSON(w)
+	})
+}
+
+func (sh *statsHandler) handleErr(err error) *response.Response {
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return sh.response.Error(err).SetCode(http.StatusBadRequest)
+	}
+
+	log.Println("[ERROR]", err)
+	return sh.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
+}
+
+func NewHandler(statsService stats.StatsService) StatsHandler {
+	return &statsHandler{
+		statsService: statsService,
+		response:     new(response.Response),
+	}
+}
@@ -0,0 +1,7 @@
+package stats
+
+import "net/http"
+
+type StatsHandler interface {
+	GetWriterStats() http.Handler
+}
@@ -2,15 +2,19 @@ package story
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gorilla/mux"
 	model "github.com/mrizkimaulidan/storial/internal/model/story"
 	"github.com/mrizkimaulidan/storial/internal/service/story"
+	"github.com/mrizkimaulidan/storial/pkg/epub"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/story"
 	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 	"github.com/mrizkimaulidan/storial/pkg/response"
 )
 
@@ -24,28 +28,44 @@ func (sh *storyHandler) FilterByCategorySlug() http.Handler {
 		vars := mux.Vars(r)
 		categorySlug := vars["categorySlug"]
 		filterType := r.URL.Query().Get("filter")
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
 
-		categoriesRespone, err := sh.storyService.FilterStoryByCategorySlug(r.Context(), categorySlug, filterType)
+		categoriesRespone, meta, err := sh.storyService.FilterStoryByCategorySlug(r.Context(), user.Viewer(), categorySlug, filterType, pagination.NewRequest(r.URL.Query()))
 		if err != nil {
 			sh.handleErr(err).JSON(w)
 			return
 		}
 
-		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(categoriesRespone).JSON(w)
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(categoriesRespone).SetMeta(meta).JSON(w)
 	})
 }
 
 func (sh *storyHandler) Filter() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		filterType := r.URL.Query().Get("filter")
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		storiesResponse, meta, err := sh.storyService.FilterStory(r.Context(), user.Viewer(), filterType, pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			sh.handleErr(err).JSON(w)
+			return
+		}
+
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(storiesResponse).SetMeta(meta).JSON(w)
+	})
+}
 
-		storiesResponse, err := sh.storyService.FilterStory(r.Context(), filterType)
+func (sh *storyHandler) Feed() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		storiesResponse, meta, err := sh.storyService.GetFeed(r.Context(), user.Viewer(), pagination.NewRequest(r.URL.Query()))
 		if err != nil {
 			sh.handleErr(err).JSON(w)
 			return
 		}
 
-		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(storiesResponse).JSON(w)
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(storiesResponse).SetMeta(meta).JSON(w)
 	})
 }
 
@@ -53,13 +73,13 @@ func (sh *storyHandler) GetAll() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
 
-		storiesResponse, err := sh.storyService.GetAllStory(r.Context(), user.Id)
+		storiesResponse, meta, err := sh.storyService.GetAllStory(r.Context(), user.Id, pagination.NewRequest(r.URL.Query()))
 		if err != nil {
 			sh.handleErr(err).JSON(w)
 			return
 		}
 
-		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(storiesResponse).JSON(w)
+		sh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(storiesResponse).SetMeta(meta).JSON(w)
 	})
 }
 
@@ -96,6 +116,7 @@ func (sh *storyHandler) Store() http.Handler {
 			Cover:           file,
 			CoverFileheader: fileheader,
 			IsPublished:     r.PostFormValue("isPublished"),
+			PublishAt:       r.PostFormValue("publishAt"),
 		}
 
 		storyResponse, err := sh.storyService.AddStory(r.Context(), request)
@@ -125,6 +146,7 @@ func (sh *storyHandler) Update() http.Handler {
 			Cover:           file,
 			CoverFileheader: fileheader,
 			IsPublished:     r.PostFormValue("isPublished"),
+			PublishAt:       r.PostFormValue("publishAt"),
 		}
 
 		storyResponse, err := sh.storyService.EditStory(r.Context(), request)
@@ -141,12 +163,13 @@ func (sh *storyHandler) LoadImageCover() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 
-		imageBytes, err := sh.storyService.LoadStoryImageCover(r.Context(), vars["filename"])
+		imageBytes, err := sh.storyService.LoadStoryImageCover(r.Context(), vars["filename"], r.URL.Query().Get("variant"))
 		if err != nil {
 			sh.handleErr(err).JSON(w)
 			return
 		}
 
+		w.Header().Set("Content-Type", "image/jpeg")
 		w.Write(imageBytes)
 	})
 }
@@ -155,8 +178,17 @@ func (sh *storyHandler) GetBySlug() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		slug := vars["slug"]
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		storyResponse, err := sh.storyService.GetStoryBySlug(r.Context(), user.Viewer(), slug)
+
+		// the story has been renamed
+		var moved *exception.SlugMovedError
+		if errors.As(err, &moved) {
+			response.Redirect(w, r, "/api/v1/book/"+url.PathEscape(moved.StorySlug))
+			return
+		}
 
-		storyResponse, err := sh.storyService.GetStoryBySlug(r.Context(), slug)
 		if err != nil {
 			sh.handleErr(err).JSON(w)
 			return
@@ -166,14 +198,55 @@ func (sh *storyHandler) GetBySlug() http.Handler {
 	})
 }
 
+func (sh *storyHandler) ExportEpub() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		slug := vars["slug"]
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		export, err := sh.storyService.PrepareExport(r.Context(), user.Viewer(), slug)
+
+		// the story has been renamed
+		var moved *exception.SlugMovedError
+		if errors.As(err, &moved) {
+			response.Redirect(w, r, "/api/v1/book/"+url.PathEscape(moved.StorySlug)+"/export.epub")
+			return
+		}
+
+		if err != nil {
+			sh.handleErr(err).JSON(w)
+			return
+		}
+
+		w.Header().Set("Content-Type", epub.MEDIA_TYPE)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename))
+
+		// the response has been started, the error can only be logged
+		err = sh.storyService.WriteEpub(r.Context(), *export, w)
+		if err != nil {
+			log.Println("[ERROR]", err)
+		}
+	})
+}
+
 func (sh *storyHandler) handleErr(err error) *response.Response {
 	switch {
 	case errors.As(err, &validation.Errors{}):
 		return sh.response.Error(err).SetCode(http.StatusBadRequest)
 	case errors.Is(err, exception.ErrStoryNotFound):
 		return sh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrAdultContentRestricted):
+		return sh.response.Error(err).SetCode(http.StatusForbidden)
 	case errors.Is(err, exception.ErrCoverImageNotFound):
 		return sh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrCoverImageInvalid):
+		return sh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCoverImageTooLarge):
+		return sh.response.Error(err).SetCode(http.StatusRequestEntityTooLarge)
+	case errors.Is(err, exception.ErrSlugTaken):
+		return sh.response.Error(err).SetCode(http.StatusConflict)
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return sh.response.Error(err).SetCode(http.StatusBadRequest)
 	}
 
 	log.Println("[ERROR]", err)
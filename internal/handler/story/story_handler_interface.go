@@ -9,6 +9,8 @@ type StoryHandler interface {
 	LoadImageCover() http.Handler
 	Delete() http.Handler
 	GetBySlug() http.Handler
+	ExportEpub() http.Handler
 	FilterByCategorySlug() http.Handler
 	Filter() http.Handler
+	Feed() http.Handler
 }
@@ -0,0 +1,195 @@
+package user
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gorilla/mux"
+	model "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/internal/service/user"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/user"
+	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/response"
+)
+
+type userHandler struct {
+	userService user.UserService
+	response    *response.Response
+}
+
+func (uh *userHandler) GetByUsername() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		userResponse, err := uh.userService.GetProfileByUsername(r.Context(), vars["username"])
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(userResponse).JSON(w)
+	})
+}
+
+func (uh *userHandler) UpdateProfile() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			uh.response.Error(err).SetCode(http.StatusBadRequest).JSON(w)
+			return
+		}
+
+		request := model.UpdateProfileRequest{
+			UserID:      user.Id,
+			Name:        formValue(r, "name"),
+			Sex:         formValue(r, "sex"),
+			Bio:         formValue(r, "bio"),
+			DateOfBirth: formValue(r, "dateOfBirth"),
+			PhoneNumber: formValue(r, "phoneNumber"),
+			Twitter:     formValue(r, "twitter"),
+			Instagram:   formValue(r, "instagram"),
+			Facebook:    formValue(r, "facebook"),
+
+			ShowAdultContent: formValue(r, "showAdultContent"),
+		}
+
+		userResponse, err := uh.userService.EditProfile(r.Context(), request)
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(userResponse).JSON(w)
+	})
+}
+
+func (uh *userHandler) UpdateRole() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		request := model.UpdateRoleRequest{
+			ActorID:  user.Id,
+			Username: vars["username"],
+			Role:     r.PostFormValue("role"),
+		}
+
+		roleResponse, err := uh.userService.EditRole(r.Context(), request)
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(roleResponse).JSON(w)
+	})
+}
+
+func (uh *userHandler) Follow() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		request := model.FollowRequest{
+			FollowerID: user.Id,
+			Username:   vars["username"],
+		}
+
+		followResponse, err := uh.userService.Follow(r.Context(), request)
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(followResponse).JSON(w)
+	})
+}
+
+func (uh *userHandler) Unfollow() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+		vars := mux.Vars(r)
+
+		request := model.FollowRequest{
+			FollowerID: user.Id,
+			Username:   vars["username"],
+		}
+
+		followResponse, err := uh.userService.Unfollow(r.Context(), request)
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(followResponse).JSON(w)
+	})
+}
+
+func (uh *userHandler) GetFollowers() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		usersResponse, meta, err := uh.userService.GetFollowers(r.Context(), vars["username"], pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(usersResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+func (uh *userHandler) GetFollowing() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		usersResponse, meta, err := uh.userService.GetFollowing(r.Context(), vars["username"], pagination.NewRequest(r.URL.Query()))
+		if err != nil {
+			uh.handleErr(err).JSON(w)
+			return
+		}
+
+		uh.response.SetCode(http.StatusOK).SetMessage("OK").SetData(usersResponse).SetMeta(meta).JSON(w)
+	})
+}
+
+// Get the form value, nil if the key is not sent on request body.
+func formValue(r *http.Request, key string) *string {
+	values, ok := r.PostForm[key]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+
+	return &values[0]
+}
+
+func (uh *userHandler) handleErr(err error) *response.Response {
+	switch {
+	case errors.As(err, &validation.Errors{}):
+		return uh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrUserNotFound):
+		return uh.response.Error(err).SetCode(http.StatusNotFound)
+	case errors.Is(err, exception.ErrCannotFollowYourself):
+		return uh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCannotChangeOwnRole):
+		return uh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, exception.ErrAgeNotVerified):
+		return uh.response.Error(err).SetCode(http.StatusBadRequest)
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return uh.response.Error(err).SetCode(http.StatusBadRequest)
+	}
+
+	log.Println("[ERROR]", err)
+	return uh.response.Error(err).SetCode(http.StatusInternalServerError).SetMessage("internal server error")
+}
+
+func NewHandler(us user.UserService) UserHandler {
+	return &userHandler{
+		userService: us,
+		response:    new(response.Response),
+	}
+}
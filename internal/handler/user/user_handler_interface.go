@@ -0,0 +1,13 @@
+package user
+
+import "net/http"
+
+type UserHandler interface {
+	GetByUsername() http.Handler
+	UpdateProfile() http.Handler
+	UpdateRole() http.Handler
+	Follow() http.Handler
+	Unfollow() http.Handler
+	GetFollowers() http.Handler
+	GetFollowing() http.Handler
+}
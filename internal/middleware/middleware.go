@@ -2,17 +2,23 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/internal/repository/token"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/authentication"
 	jwtpkg "github.com/mrizkimaulidan/storial/pkg/jwt"
 	"github.com/mrizkimaulidan/storial/pkg/response"
 )
 
 type middleware struct {
-	response *response.Response
+	response        *response.Response
+	tokenRepository token.TokenRepository
+	db              *sql.DB
 }
 
 // JWT Authorization middleware. If no authorization token on
@@ -22,13 +28,7 @@ func (m *middleware) JWTAuthorization(n http.Handler) http.Handler {
 		authorizationHeader := r.Header.Get("Authorization")
 		t := strings.Replace(authorizationHeader, "Bearer ", "", -1)
 
-		token, err := jwt.ParseWithClaims(t, &jwtpkg.CustomClaims{}, func(t *jwt.Token) (any, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenUnverifiable
-			}
-
-			return jwtpkg.SECRET_KEY, nil
-		})
+		token, err := jwt.ParseWithClaims(t, &jwtpkg.CustomClaims{}, keyFunc)
 
 		if !strings.Contains(authorizationHeader, "Bearer ") {
 			m.response.SetCode(http.StatusUnauthorized).SetMessage(jwt.ErrInvalidKey.Error()).SetData(nil).JSON(w)
@@ -66,6 +66,20 @@ func (m *middleware) JWTAuthorization(n http.Handler) http.Handler {
 			return
 		}
 
+		if token.Valid && claims.ID != "" {
+			revoked, err := m.isRevoked(r.Context(), claims.ID)
+			if err != nil {
+				log.Println("[ERROR]", err)
+				m.response.SetCode(http.StatusInternalServerError).SetMessage("INTERNAL SERVER ERROR").SetData(nil).JSON(w)
+				return
+			}
+
+			if revoked {
+				m.response.SetCode(http.StatusUnauthorized).SetMessage(exception.ErrTokenRevoked.Error()).SetData(nil).JSON(w)
+				return
+			}
+		}
+
 		if token.Valid {
 			ctx := context.WithValue(r.Context(), jwtpkg.CtxKeyUserInformation, claims)
 			r = r.WithContext(ctx)
@@ -75,6 +89,81 @@ func (m *middleware) JWTAuthorization(n http.Handler) http.Handler {
 	})
 }
 
+// Optional JWT authorization middleware for the endpoints open to anonymous
+// readers. The claims are only attached when the request has valid and not
+// revoked Bearer token, otherwise the request is served with empty claims
+// so the user ID is zero.
+func (m *middleware) OptionalJWTAuthorization(n http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := &jwtpkg.CustomClaims{}
+
+		authorizationHeader := r.Header.Get("Authorization")
+		if strings.HasPrefix(authorizationHeader, "Bearer ") {
+			token, err := jwt.ParseWithClaims(strings.TrimPrefix(authorizationHeader, "Bearer "), &jwtpkg.CustomClaims{}, keyFunc)
+			if err == nil && token.Valid {
+				c := token.Claims.(*jwtpkg.CustomClaims)
+
+				revoked := false
+				if c.ID != "" {
+					revoked, err = m.isRevoked(r.Context(), c.ID)
+					if err != nil {
+						log.Println("[ERROR]", err)
+						m.response.SetCode(http.StatusInternalServerError).SetMessage("INTERNAL SERVER ERROR").SetData(nil).JSON(w)
+						return
+					}
+				}
+
+				if !revoked {
+					claims = c
+				}
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), jwtpkg.CtxKeyUserInformation, claims)
+		n.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Role authorization middleware. Must be used behind JWT authorization.
+// If the user role is lower than the given role, it will return forbidden status.
+func (m *middleware) RequireRole(role string) func(http.Handler) http.Handler {
+	return func(n http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(jwtpkg.CtxKeyUserInformation).(*jwtpkg.CustomClaims)
+			if !ok {
+				m.response.SetCode(http.StatusUnauthorized).SetMessage(jwt.ErrInvalidKey.Error()).SetData(nil).JSON(w)
+				return
+			}
+
+			if entity.RoleLevel(claims.Role) < entity.RoleLevel(role) {
+				m.response.SetCode(http.StatusForbidden).SetMessage(exception.ErrForbidden.Error()).SetData(nil).JSON(w)
+				return
+			}
+
+			n.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Checking the access token ID (jti) has been revoked on logout.
+func (m *middleware) isRevoked(ctx context.Context, jti string) (bool, error) {
+	revoked, err := m.tokenRepository.CheckIfAccessTokenRevoked(ctx, m.db, jti)
+	if err != nil {
+		return false, err
+	}
+
+	return *revoked, nil
+}
+
+// Get the key verifying the token signature, only HMAC signed token is accepted.
+func keyFunc(t *jwt.Token) (any, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+
+	return jwtpkg.SECRET_KEY, nil
+}
+
 // Logging incoming request to terminal.
 func (m *middleware) LoggingMiddleware(n http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -84,8 +173,10 @@ func (m *middleware) LoggingMiddleware(n http.Handler) http.Handler {
 	})
 }
 
-func New() *middleware {
+func New(db *sql.DB) *middleware {
 	return &middleware{
-		response: new(response.Response),
+		response:        new(response.Response),
+		tokenRepository: token.NewRepository(),
+		db:              db,
 	}
 }
@@ -24,12 +24,14 @@ func (rr *RegisterRequest) Validate() error {
 }
 
 type RegisterResponse struct {
-	Id       uint64 `json:"id"`
-	Name     string `json:"name"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Token    string `json:"token"`
-	Sex      string `json:"sex"`
+	Id           uint64 `json:"id"`
+	Name         string `json:"name"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	Sex          string `json:"sex"`
+	Role         string `json:"role"`
 }
 
 type LoginRequest struct {
@@ -45,10 +47,45 @@ func (lr *LoginRequest) Validate() error {
 }
 
 type LoginResponse struct {
-	Id       uint64 `json:"id"`
-	Name     string `json:"name"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Token    string `json:"token"`
-	Sex      string `json:"sex"`
+	Id           uint64 `json:"id"`
+	Name         string `json:"name"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	Sex          string `json:"sex"`
+	Role         string `json:"role"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string
+}
+
+func (rtr *RefreshTokenRequest) Validate() error {
+	return validation.ValidateStruct(rtr,
+		validation.Field(&rtr.RefreshToken, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LogoutRequest struct {
+	UserID         uint64
+	TokenID        string
+	TokenExpiresAt uint64
+	RefreshToken   string
+}
+
+func (lr *LogoutRequest) Validate() error {
+	return validation.ValidateStruct(lr,
+		validation.Field(&lr.UserID, validation.Required),
+		validation.Field(&lr.RefreshToken, validation.Length(0, 255)),
+	)
+}
+
+type LogoutResponse struct {
+	Status bool `json:"status"`
 }
@@ -1,9 +1,12 @@
 package category
 
+import validation "github.com/go-ozzo/ozzo-validation/v4"
+
 type CategoryResponse struct {
 	Id          uint64 `json:"id"`
 	Slug        string `json:"slug"`
 	Name        string `json:"name"`
+	Position    uint64 `json:"position"`
 	StoryCounts uint64 `json:"storyCounts"`
 }
 
@@ -12,3 +15,55 @@ type CategoryResponseByStorySlug struct {
 	Slug string `json:"slug"`
 	Name string `json:"name"`
 }
+
+type CreateCategoryRequest struct {
+	Name string
+}
+
+func (ccr *CreateCategoryRequest) Validate() error {
+	return validation.ValidateStruct(ccr,
+		validation.Field(&ccr.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+type UpdateCategoryRequest struct {
+	CategoryID string
+	Name       string
+}
+
+func (ucr *UpdateCategoryRequest) Validate() error {
+	return validation.ValidateStruct(ucr,
+		validation.Field(&ucr.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+// Request to delete the category. When the category still has stories,
+// the ReassignTo is the category ID where the stories will be moved.
+type DeleteCategoryRequest struct {
+	CategoryID string
+	ReassignTo string
+}
+
+// Request to reorder the categories. The category IDs are ordered
+// from the first position.
+type ReorderCategoryRequest struct {
+	CategoryIDs []string
+}
+
+func (rcr *ReorderCategoryRequest) Validate() error {
+	return validation.ValidateStruct(rcr,
+		validation.Field(&rcr.CategoryIDs, validation.Required),
+	)
+}
+
+type CategoryResponseByAdmin struct {
+	Id       uint64 `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Position uint64 `json:"position"`
+}
+
+type DeletedCategoryResponse struct {
+	Status            bool   `json:"status"`
+	ReassignedStories uint64 `json:"reassignedStories"`
+}
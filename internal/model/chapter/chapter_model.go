@@ -1,20 +1,35 @@
 package chapter
 
 import (
+	"regexp"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	"github.com/mrizkimaulidan/storial/internal/model/story"
 	"github.com/mrizkimaulidan/storial/internal/model/user"
 )
 
+// Representations of the chapter body returned by the format query,
+// either the body source as written or the rendered HTML.
+var (
+	FORMAT_SOURCE = "source"
+	FORMAT_HTML   = "html"
+)
+
+// Maximum length of the Markdown body in bytes, the body is rendered
+// into HTML on every save.
+var MARKDOWN_BODY_MAX_LENGTH = 1 << 20 // 1 MB
+
 type CreateChapterRequest struct {
 	UserID        uint64
 	StorySlug     string
 	Title         string
 	Body          string
+	Format        string
 	AuthorComment string
 	IsPublished   string
+	PublishAt     string
 }
 
 func (ccr *CreateChapterRequest) Validate() error {
@@ -22,25 +37,32 @@ func (ccr *CreateChapterRequest) Validate() error {
 		validation.Field(&ccr.UserID, validation.Required),
 		validation.Field(&ccr.StorySlug, validation.Required, validation.Length(5, 255)),
 		validation.Field(&ccr.Title, validation.Required, validation.Length(5, 255)),
-		validation.Field(&ccr.Body, validation.Required, validation.Length(5, 4294967295)),
+		validation.Field(&ccr.Body, validation.Required, validation.Length(5, 4294967295),
+			validation.When(ccr.Format == entity.BODY_FORMAT_MARKDOWN, validation.Length(5, MARKDOWN_BODY_MAX_LENGTH))),
+		validation.Field(&ccr.Format, validation.In(entity.BODY_FORMAT_PLAIN, entity.BODY_FORMAT_MARKDOWN)),
 		validation.Field(&ccr.AuthorComment, validation.Length(0, 255)),
 		validation.Field(&ccr.IsPublished, validation.Required, validation.In("0", "1")),
+		validation.Field(&ccr.PublishAt, validation.Date(story.PUBLISH_AT_LAYOUT).Min(time.Now()).Error("must be a valid future date in RFC3339 format")),
 	)
 }
 
 type CreatedChapterdResponse struct {
-	Id            uint64    `json:"id"`
-	StoryID       uint64    `json:"storyId"`
-	Title         string    `json:"title"`
-	Slug          string    `json:"slug"`
-	Body          string    `json:"body"`
-	AuthorComment string    `json:"authorComment"`
-	WordCounts    uint64    `json:"wordCounts"`
-	Likes         uint64    `json:"likes"`
-	ReadingTime   string    `json:"readingTime"`
-	IsPublished   bool      `json:"isPublished"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	Id            uint64     `json:"id"`
+	StoryID       uint64     `json:"storyId"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Body          string     `json:"body"`
+	BodyFormat    string     `json:"bodyFormat"`
+	BodyHTML      string     `json:"bodyHtml"`
+	AuthorComment string     `json:"authorComment"`
+	WordCounts    uint64     `json:"wordCounts"`
+	Likes         uint64     `json:"likes"`
+	ReadingTime   uint64     `json:"readingTime"`
+	IsPublished   bool       `json:"isPublished"`
+	PublishAt     *time.Time `json:"publishAt"`
+	Position      uint64     `json:"position"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 type UpdateChapterRequest struct {
@@ -49,8 +71,10 @@ type UpdateChapterRequest struct {
 	ChapterSlug   string
 	Title         string
 	Body          string
+	Format        string
 	AuthorComment string
 	IsPublished   string
+	PublishAt     string
 }
 
 func (ucr *UpdateChapterRequest) Validate() error {
@@ -59,25 +83,31 @@ func (ucr *UpdateChapterRequest) Validate() error {
 		validation.Field(&ucr.StorySlug, validation.Required),
 		validation.Field(&ucr.ChapterSlug, validation.Required),
 		validation.Field(&ucr.Title, validation.Required, validation.Length(5, 255)),
-		validation.Field(&ucr.Body, validation.Required, validation.Length(5, 4294967295)),
+		validation.Field(&ucr.Body, validation.Required, validation.Length(5, 4294967295),
+			validation.When(ucr.Format == entity.BODY_FORMAT_MARKDOWN, validation.Length(5, MARKDOWN_BODY_MAX_LENGTH))),
+		validation.Field(&ucr.Format, validation.In(entity.BODY_FORMAT_PLAIN, entity.BODY_FORMAT_MARKDOWN)),
 		validation.Field(&ucr.AuthorComment, validation.Length(0, 255)),
 		validation.Field(&ucr.IsPublished, validation.In("0", "1")),
+		validation.Field(&ucr.PublishAt, validation.Date(story.PUBLISH_AT_LAYOUT).Min(time.Now()).Error("must be a valid future date in RFC3339 format")),
 	)
 }
 
 type UpdatedChapterResponse struct {
-	Id            uint64    `json:"id"`
-	StoryID       uint64    `json:"storyId"`
-	Title         string    `json:"title"`
-	Slug          string    `json:"slug"`
-	Body          string    `json:"body"`
-	AuthorComment string    `json:"authorComment"`
-	WordCounts    uint64    `json:"wordCounts"`
-	Likes         uint64    `json:"likes"`
-	ReadingTime   string    `json:"readingTime"`
-	IsPublished   bool      `json:"isPublished"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	Id            uint64     `json:"id"`
+	StoryID       uint64     `json:"storyId"`
+	Title         string     `json:"title"`
+	Slug          string     `json:"slug"`
+	Body          string     `json:"body"`
+	BodyFormat    string     `json:"bodyFormat"`
+	BodyHTML      string     `json:"bodyHtml"`
+	AuthorComment string     `json:"authorComment"`
+	WordCounts    uint64     `json:"wordCounts"`
+	Likes         uint64     `json:"likes"`
+	ReadingTime   uint64     `json:"readingTime"`
+	IsPublished   bool       `json:"isPublished"`
+	PublishAt     *time.Time `json:"publishAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 type ChapterResponse struct {
@@ -91,7 +121,7 @@ type ChapterResponse struct {
 	AuthorComment string                       `json:"authorComment"`
 	WordCounts    uint64                       `json:"wordCounts"`
 	Likes         uint64                       `json:"likes"`
-	ReadingTime   string                       `json:"readingTime"`
+	ReadingTime   uint64                       `json:"readingTime"`
 	IsPublished   bool                         `json:"isPublished"`
 	CreatedAt     time.Time                    `json:"createdAt"`
 	UpdatedAt     time.Time                    `json:"updatedAt"`
@@ -105,19 +135,84 @@ type ChapterResponseByStorySlugAndChapterSlug struct {
 	Title         string                       `json:"title"`
 	Slug          string                       `json:"slug"`
 	Body          string                       `json:"body"`
+	BodyFormat    string                       `json:"bodyFormat"`
+	Format        string                       `json:"format"`
 	AuthorComment string                       `json:"authorComment"`
 	Likes         uint64                       `json:"likes"`
-	ReadingTime   string                       `json:"readingTime"`
+	LikedByMe     bool                         `json:"likedByMe"`
+	ReadingTime   uint64                       `json:"readingTime"`
+	Position      uint64                       `json:"position"`
+	Previous      *ChapterResponseByNavigation `json:"previous"`
+	Next          *ChapterResponseByNavigation `json:"next"`
 	UpdatedAt     time.Time                    `json:"updatedAt"`
 }
 
+type ChapterResponseByNavigation struct {
+	Id       uint64 `json:"id"`
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Position uint64 `json:"position"`
+}
+
 type ChapterResponseBySlug struct {
 	Id         uint64 `json:"id"`
 	StoryID    uint64 `json:"storyId"`
 	Title      string `json:"title"`
 	Slug       string `json:"slug"`
+	Position   uint64 `json:"position"`
 	WordCounts uint64 `json:"wordCounts"`
 	Likes      uint64 `json:"likes"`
+	LikedByMe  bool   `json:"likedByMe"`
+	Comments   uint64 `json:"comments"`
+}
+
+// Request to reorder the chapters of the story. The chapter IDs are
+// ordered from the first chapter.
+type ReorderChapterRequest struct {
+	UserID     uint64
+	StoryID    string
+	ChapterIDs []string
+}
+
+func (rcr *ReorderChapterRequest) Validate() error {
+	return validation.ValidateStruct(rcr,
+		validation.Field(&rcr.UserID, validation.Required),
+		validation.Field(&rcr.ChapterIDs, validation.Required),
+	)
+}
+
+type RevisionResponse struct {
+	Id            uint64    `json:"id"`
+	ChapterID     uint64    `json:"chapterId"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body,omitempty"`
+	BodyFormat    string    `json:"bodyFormat,omitempty"`
+	AuthorComment string    `json:"authorComment"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Request to diff two versions of the chapter body. The From and To
+// are revision ID, or "current" for the current chapter.
+type DiffRevisionRequest struct {
+	UserID    uint64
+	StoryID   string
+	ChapterID string
+	From      string
+	To        string
+}
+
+func (drr *DiffRevisionRequest) Validate() error {
+	return validation.ValidateStruct(drr,
+		validation.Field(&drr.UserID, validation.Required),
+		validation.Field(&drr.From, validation.Required, validation.Match(regexp.MustCompile(`^([0-9]+|current)$`))),
+		validation.Field(&drr.To, validation.Match(regexp.MustCompile(`^([0-9]+|current)$`))),
+	)
+}
+
+type DiffRevisionResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Diff string `json:"diff"`
 }
 
 type DeletedChapterResponse struct {
@@ -125,5 +220,7 @@ type DeletedChapterResponse struct {
 }
 
 type LikedChapterResponse struct {
-	Status bool `json:"status"`
+	Status    bool   `json:"status"`
+	Likes     uint64 `json:"likes"`
+	LikedByMe bool   `json:"likedByMe"`
 }
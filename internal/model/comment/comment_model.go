@@ -0,0 +1,60 @@
+package comment
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mrizkimaulidan/storial/internal/model/user"
+)
+
+type CreateCommentRequest struct {
+	UserID    uint64
+	StoryID   string
+	ChapterID string
+	ParentID  string
+	Body      string
+}
+
+func (ccr *CreateCommentRequest) Validate() error {
+	return validation.ValidateStruct(ccr,
+		validation.Field(&ccr.UserID, validation.Required),
+		validation.Field(&ccr.Body, validation.Required, validation.Length(1, 5000)),
+	)
+}
+
+type UpdateCommentRequest struct {
+	UserID    uint64
+	StoryID   string
+	ChapterID string
+	CommentID string
+	Body      string
+}
+
+func (ucr *UpdateCommentRequest) Validate() error {
+	return validation.ValidateStruct(ucr,
+		validation.Field(&ucr.UserID, validation.Required),
+		validation.Field(&ucr.Body, validation.Required, validation.Length(1, 5000)),
+	)
+}
+
+type DeleteCommentRequest struct {
+	UserID    uint64
+	StoryID   string
+	ChapterID string
+	CommentID string
+}
+
+type CommentResponse struct {
+	Id        uint64                     `json:"id"`
+	ChapterID uint64                     `json:"chapterId"`
+	ParentID  *uint64                    `json:"parentId"`
+	User      user.UserResponseByChapter `json:"user"`
+	Body      string                     `json:"body"`
+	Replies   []CommentResponse          `json:"replies,omitempty"`
+	CreatedAt time.Time                  `json:"createdAt"`
+	UpdatedAt time.Time                  `json:"updatedAt"`
+}
+
+type DeletedCommentResponse struct {
+	Status bool `json:"status"`
+}
@@ -0,0 +1,57 @@
+package library
+
+import (
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/mrizkimaulidan/storial/internal/model/user"
+)
+
+type UpdateProgressRequest struct {
+	UserID         uint64
+	StoryID        string
+	ChapterID      string
+	ScrollPosition string
+}
+
+func (upr *UpdateProgressRequest) Validate() error {
+	return validation.ValidateStruct(upr,
+		validation.Field(&upr.UserID, validation.Required),
+		validation.Field(&upr.ChapterID, validation.Required, is.Digit),
+		validation.Field(&upr.ScrollPosition, validation.Match(regexp.MustCompile(`^(100|[1-9]?[0-9])$`)).Error("must be a number between 0 and 100")),
+	)
+}
+
+type ChapterResponseByProgress struct {
+	Id    uint64 `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+type ProgressResponse struct {
+	StoryID        uint64                    `json:"storyId"`
+	Chapter        ChapterResponseByProgress `json:"chapter"`
+	ScrollPosition uint64                    `json:"scrollPosition"`
+	Percentage     float64                   `json:"percentage"`
+	UpdatedAt      time.Time                 `json:"updatedAt"`
+}
+
+type StoryResponseByLibrary struct {
+	Id      uint64                     `json:"id"`
+	User    user.UserResponseByChapter `json:"user"`
+	Title   string                     `json:"title"`
+	Slug    string                     `json:"slug"`
+	IsAdult bool                       `json:"isAdult"`
+}
+
+type LibraryResponse struct {
+	Story        StoryResponseByLibrary `json:"story"`
+	Progress     *ProgressResponse      `json:"progress"`
+	BookmarkedAt time.Time              `json:"bookmarkedAt"`
+}
+
+type BookmarkResponse struct {
+	Bookmarked bool `json:"bookmarked"`
+}
@@ -0,0 +1,33 @@
+package search
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	storymodel "github.com/mrizkimaulidan/storial/internal/model/story"
+	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
+)
+
+type SearchRequest struct {
+	Query        string
+	IncludeAdult bool
+}
+
+func (sr *SearchRequest) Validate() error {
+	return validation.ValidateStruct(sr,
+		validation.Field(&sr.Query, validation.Required, validation.Length(2, 255)),
+	)
+}
+
+type SearchChapterResponse struct {
+	Id    uint64 `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+type SearchResponse struct {
+	Type    string                            `json:"type"`
+	Score   float64                           `json:"score"`
+	Story   storymodel.StoryResponseByChapter `json:"story"`
+	Chapter *SearchChapterResponse            `json:"chapter,omitempty"`
+	User    usermodel.UserResponseByFilter    `json:"user"`
+	Snippet string                            `json:"snippet"`
+}
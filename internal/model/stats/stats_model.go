@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Default number of days of the likes over time.
+var DEFAULT_DAYS = "30"
+
+// Layout of the day on the likes over time.
+var DAY_LAYOUT = "2006-01-02"
+
+type StatsRequest struct {
+	UserID uint64
+	Days   string
+}
+
+func (sr *StatsRequest) Validate() error {
+	return validation.ValidateStruct(sr,
+		validation.Field(&sr.UserID, validation.Required),
+		validation.Field(&sr.Days, validation.Match(regexp.MustCompile(`^([1-9]|[1-9][0-9]|[12][0-9]{2}|3[0-5][0-9]|36[0-5])$`)).Error("must be a number between 1 and 365")),
+	)
+}
+
+type DailyLikesResponse struct {
+	Date  string `json:"date"`
+	Likes uint64 `json:"likes"`
+}
+
+type ChapterStatsResponse struct {
+	Id                uint64               `json:"id"`
+	Title             string               `json:"title"`
+	Slug              string               `json:"slug"`
+	IsPublished       bool                 `json:"isPublished"`
+	Position          uint64               `json:"position"`
+	Views             uint64               `json:"views"`
+	UniqueReaders     uint64               `json:"uniqueReaders"`
+	Likes             uint64               `json:"likes"`
+	LikesOverTime     []DailyLikesResponse `json:"likesOverTime"`
+	Comments          uint64               `json:"comments"`
+	AverageCompletion float64              `json:"averageCompletion"`
+}
+
+type StoryStatsResponse struct {
+	Id                uint64                 `json:"id"`
+	Title             string                 `json:"title"`
+	Slug              string                 `json:"slug"`
+	IsPublished       bool                   `json:"isPublished"`
+	Views             uint64                 `json:"views"`
+	UniqueReaders     uint64                 `json:"uniqueReaders"`
+	Likes             uint64                 `json:"likes"`
+	LikesOverTime     []DailyLikesResponse   `json:"likesOverTime"`
+	Comments          uint64                 `json:"comments"`
+	AverageCompletion float64                `json:"averageCompletion"`
+	Chapters          []ChapterStatsResponse `json:"chapters"`
+}
+
+type WriterStatsResponse struct {
+	Days    uint64               `json:"days"`
+	Stories []StoryStatsResponse `json:"stories"`
+}
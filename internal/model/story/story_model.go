@@ -9,6 +9,9 @@ import (
 	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
 )
 
+// Layout of the scheduled publish time.
+var PUBLISH_AT_LAYOUT = time.RFC3339
+
 type CreateStoryRequest struct {
 	UserID          uint64
 	CategoryID      string
@@ -18,6 +21,7 @@ type CreateStoryRequest struct {
 	CoverFileheader *multipart.FileHeader
 	IsAdult         string
 	IsPublished     string
+	PublishAt       string
 }
 
 func (cr *CreateStoryRequest) Validate() error {
@@ -28,6 +32,7 @@ func (cr *CreateStoryRequest) Validate() error {
 		validation.Field(&cr.Description, validation.Required, validation.Length(5, 16777215)),
 		validation.Field(&cr.IsAdult, validation.Required, validation.In("0", "1")),
 		validation.Field(&cr.IsPublished, validation.Required, validation.In("0", "1")),
+		validation.Field(&cr.PublishAt, validation.Date(PUBLISH_AT_LAYOUT).Min(time.Now()).Error("must be a valid future date in RFC3339 format")),
 	)
 }
 
@@ -41,7 +46,8 @@ type CreatedStoryResponse struct {
 	Description string                         `json:"description"`
 	IsAdult     bool                           `json:"isAdult"`
 	IsPublished bool                           `json:"isPublished"`
-	Cover       string                         `json:"cover"`
+	PublishAt   *time.Time                     `json:"publishAt"`
+	Cover       *CoverResponse                 `json:"cover"`
 	CreatedAt   time.Time                      `json:"createdAt"`
 	UpdatedAt   time.Time                      `json:"updatedAt"`
 }
@@ -56,6 +62,7 @@ type UpdateStoryRequest struct {
 	CoverFileheader *multipart.FileHeader
 	IsAdult         string
 	IsPublished     string
+	PublishAt       string
 }
 
 func (usr *UpdateStoryRequest) Validate() error {
@@ -66,6 +73,7 @@ func (usr *UpdateStoryRequest) Validate() error {
 		validation.Field(&usr.Description, validation.Required, validation.Length(5, 16777215)),
 		validation.Field(&usr.IsAdult, validation.Required, validation.In("0", "1")),
 		validation.Field(&usr.IsPublished, validation.Required, validation.In("0", "1")),
+		validation.Field(&usr.PublishAt, validation.Date(PUBLISH_AT_LAYOUT).Min(time.Now()).Error("must be a valid future date in RFC3339 format")),
 	)
 }
 
@@ -79,11 +87,19 @@ type UpdatedStoryResponse struct {
 	Description string                         `json:"description"`
 	IsAdult     bool                           `json:"isAdult"`
 	IsPublished bool                           `json:"isPublished"`
-	Cover       string                         `json:"cover"`
+	PublishAt   *time.Time                     `json:"publishAt"`
+	Cover       *CoverResponse                 `json:"cover"`
 	CreatedAt   time.Time                      `json:"createdAt"`
 	UpdatedAt   time.Time                      `json:"updatedAt"`
 }
 
+// Cover image URL of every variant.
+type CoverResponse struct {
+	Thumbnail string `json:"thumbnail"`
+	Card      string `json:"card"`
+	Full      string `json:"full"`
+}
+
 type DeleteStoryRequest struct {
 	Id string
 }
@@ -99,6 +115,7 @@ type StoryResponse struct {
 	Title       string                 `json:"title"`
 	Slug        string                 `json:"slug"`
 	IsPublished bool                   `json:"isPublished"`
+	PublishAt   *time.Time             `json:"publishAt"`
 	CreatedAt   time.Time              `json:"createdAt"`
 	UpdatedAt   time.Time              `json:"updatedAt"`
 }
@@ -118,11 +135,25 @@ type StoryResponseBySlug struct {
 	Title         string                                    `json:"title"`
 	Slug          string                                    `json:"slug"`
 	ChapterCounts uint64                                    `json:"chapterCounts"`
-	ReadingTime   string                                    `json:"readingTime"`
+	ReadingTime   uint64                                    `json:"readingTime"`
 	CreatedAt     time.Time                                 `json:"createdAt"`
 	UpdatedAt     time.Time                                 `json:"updatedAt"`
 }
 
+// Story prepared to be exported. The chapters are only referenced by ID,
+// so they are loaded one by one while the book is written.
+type StoryExport struct {
+	Id          uint64
+	Title       string
+	Slug        string
+	Description string
+	Author      string
+	Cover       string
+	ChapterIDs  []uint64
+	UpdatedAt   time.Time
+	Filename    string
+}
+
 type StoryResponseByFilter struct {
 	Id            uint64                         `json:"id"`
 	UserID        uint64                         `json:"userId"`
@@ -131,7 +162,7 @@ type StoryResponseByFilter struct {
 	Slug          string                         `json:"slug"`
 	ChapterCounts uint64                         `json:"chapterCounts"`
 	IsAdult       bool                           `json:"isAdult"`
-	Cover         string                         `json:"cover"`
+	Cover         *CoverResponse                 `json:"cover"`
 	CreatedAt     time.Time                      `json:"createdAt"`
 }
 
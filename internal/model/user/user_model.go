@@ -1,6 +1,15 @@
 package user
 
-import "time"
+import (
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	DATE_OF_BIRTH_LAYOUT = "2006-01-02"
+)
 
 type UserResponse struct {
 	Id    uint64 `json:"id"`
@@ -33,3 +42,105 @@ type UserResponseByLatest struct {
 	ChapterCounts uint64    `json:"chapterCounts"`
 	CreatedAt     time.Time `json:"createdAt"`
 }
+
+type UserResponseByUsername struct {
+	Id              uint64    `json:"id"`
+	Name            string    `json:"name"`
+	Username        string    `json:"username"`
+	Sex             string    `json:"sex"`
+	Role            string    `json:"role"`
+	Bio             string    `json:"bio"`
+	Twitter         string    `json:"twitter"`
+	Instagram       string    `json:"instagram"`
+	Facebook        string    `json:"facebook"`
+	StoryCounts     uint64    `json:"storyCounts"`
+	ChapterCounts   uint64    `json:"chapterCounts"`
+	FollowerCounts  uint64    `json:"followerCounts"`
+	FollowingCounts uint64    `json:"followingCounts"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Request for editing profile. Every field is optional,
+// nil field means the field is not changed and empty string clears it.
+type UpdateProfileRequest struct {
+	UserID      uint64
+	Name        *string
+	Sex         *string
+	Bio         *string
+	DateOfBirth *string
+	PhoneNumber *string
+	Twitter     *string
+	Instagram   *string
+	Facebook    *string
+
+	ShowAdultContent *string
+}
+
+func (upr *UpdateProfileRequest) Validate() error {
+	return validation.ValidateStruct(upr,
+		validation.Field(&upr.UserID, validation.Required),
+		validation.Field(&upr.Name, validation.NilOrNotEmpty, validation.Length(5, 255)),
+		validation.Field(&upr.Sex, validation.NilOrNotEmpty, validation.In("0", "1", "2", "9")),
+		validation.Field(&upr.Bio, validation.Length(0, 1000)),
+		validation.Field(&upr.DateOfBirth, validation.Date(DATE_OF_BIRTH_LAYOUT).Max(time.Now()).Error("must be a valid past date in YYYY-MM-DD format")),
+		validation.Field(&upr.PhoneNumber, validation.Match(regexp.MustCompile(`^\+?[0-9]{8,15}$`)).Error("must be 8 to 15 digits, optionally prefixed with +")),
+		validation.Field(&upr.Twitter, validation.Match(regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)).Error("must be a valid Twitter username")),
+		validation.Field(&upr.Instagram, validation.Match(regexp.MustCompile(`^[A-Za-z0-9_.]{1,30}$`)).Error("must be a valid Instagram username")),
+		validation.Field(&upr.Facebook, validation.Match(regexp.MustCompile(`^[A-Za-z0-9.]{5,50}$`)).Error("must be a valid Facebook username")),
+		validation.Field(&upr.ShowAdultContent, validation.NilOrNotEmpty, validation.In("0", "1")),
+	)
+}
+
+type UpdatedProfileResponse struct {
+	Id          uint64    `json:"id"`
+	Name        string    `json:"name"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Sex         string    `json:"sex"`
+	Role        string    `json:"role"`
+	Bio         string    `json:"bio"`
+	DateOfBirth string    `json:"dateOfBirth"`
+	PhoneNumber string    `json:"phoneNumber"`
+	Twitter     string    `json:"twitter"`
+	Instagram   string    `json:"instagram"`
+	Facebook    string    `json:"facebook"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	ShowAdultContent bool `json:"showAdultContent"`
+}
+
+type FollowRequest struct {
+	FollowerID uint64
+	Username   string
+}
+
+type FollowResponse struct {
+	Following bool `json:"following"`
+}
+
+type UserResponseByFollow struct {
+	Id         uint64    `json:"id"`
+	Name       string    `json:"name"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followedAt"`
+}
+
+type UpdateRoleRequest struct {
+	ActorID  uint64
+	Username string
+	Role     string
+}
+
+func (urr *UpdateRoleRequest) Validate() error {
+	return validation.ValidateStruct(urr,
+		validation.Field(&urr.ActorID, validation.Required),
+		validation.Field(&urr.Username, validation.Required),
+		validation.Field(&urr.Role, validation.Required, validation.In("reader", "writer", "moderator", "admin")),
+	)
+}
+
+type UpdatedRoleResponse struct {
+	Id       uint64 `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
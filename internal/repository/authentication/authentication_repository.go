@@ -74,7 +74,10 @@ func (ar *authenticationRepository) Login(ctx context.Context, tx *sql.Tx, s ent
 		username,
 		email,
 		password,
-		sex
+		sex,
+		role,
+		date_of_birth,
+		show_adult_content
 	FROM
 		users
 	WHERE
@@ -84,7 +87,9 @@ func (ar *authenticationRepository) Login(ctx context.Context, tx *sql.Tx, s ent
 	row := tx.QueryRowContext(ctx, query, s.Email)
 
 	var user entity.User
-	err := row.Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Password, &user.Sex)
+	var dateOfBirth sql.NullInt64
+	err := row.Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Password, &user.Sex, &user.Role, &dateOfBirth,
+		&user.ShowAdultContent)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, exception.ErrEmailNotFound
@@ -92,6 +97,44 @@ func (ar *authenticationRepository) Login(ctx context.Context, tx *sql.Tx, s ent
 		return nil, err
 	}
 
+	user.DateOfBirth = &dateOfBirth
+
+	return &user, nil
+}
+
+// Find single user by ID.
+// If the ID does not exists on database, throwing an err user not found.
+func (ar *authenticationRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.User, error) {
+	query := `
+		SELECT
+		id,
+		name,
+		username,
+		email,
+		sex,
+		role,
+		date_of_birth,
+		show_adult_content
+	FROM
+		users
+	WHERE
+		id = ?
+	`
+
+	row := tx.QueryRowContext(ctx, query, id)
+
+	var user entity.User
+	var dateOfBirth sql.NullInt64
+	err := row.Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Sex, &user.Role, &dateOfBirth, &user.ShowAdultContent)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.DateOfBirth = &dateOfBirth
+
 	return &user, nil
 }
 
@@ -105,11 +148,12 @@ func (ar *authenticationRepository) Register(ctx context.Context, tx *sql.Tx, s
 			email,
 			password,
 			sex,
+			role,
 			created_at
 		)
-		VALUES(?, ?, ?, ?, ?, ?, ?)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := tx.ExecContext(ctx, query, s.Id, s.Name, s.Username, s.Email, s.Password, s.Sex, s.CreatedAt)
+	_, err := tx.ExecContext(ctx, query, s.Id, s.Name, s.Username, s.Email, s.Password, s.Sex, s.Role, s.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -12,4 +12,5 @@ type AuthenticationRepository interface {
 	CheckIfEmailExists(ctx context.Context, tx *sql.Tx, e string) (*bool, error)
 	CheckIfUsernameExists(ctx context.Context, tx *sql.Tx, u string) (*bool, error)
 	Login(ctx context.Context, tx *sql.Tx, u entity.User) (*entity.User, error)
+	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.User, error)
 }
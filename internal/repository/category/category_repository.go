@@ -5,20 +5,24 @@ import (
 	"database/sql"
 
 	"github.com/mrizkimaulidan/storial/internal/entity"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/category"
 )
 
 type categoryRepository struct {
 	//
 }
 
-// Find all category on database order by name ASC.
+// Find all category on database order by position ASC.
 func (cr *categoryRepository) FindAll(ctx context.Context, tx *sql.Tx) (*[]entity.Category, error) {
 	query := `
 		SELECT
-		*
+		id,
+		name,
+		slug,
+		position
 	FROM
 		categories
-	ORDER BY NAME ASC
+	ORDER BY position ASC, name ASC
 	`
 
 	rows, err := tx.QueryContext(ctx, query)
@@ -30,7 +34,7 @@ func (cr *categoryRepository) FindAll(ctx context.Context, tx *sql.Tx) (*[]entit
 	var categories []entity.Category
 	for rows.Next() {
 		var c entity.Category
-		err := rows.Scan(&c.Id, &c.Name, &c.Slug)
+		err := rows.Scan(&c.Id, &c.Name, &c.Slug, &c.Position)
 		if err != nil {
 			return nil, err
 		}
@@ -46,6 +50,158 @@ func (cr *categoryRepository) FindAll(ctx context.Context, tx *sql.Tx) (*[]entit
 	return &categories, nil
 }
 
+// Find single category by ID.
+// If not exists, throwing an err category not found.
+func (cr *categoryRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Category, error) {
+	query := `
+		SELECT
+		id,
+		name,
+		slug,
+		position
+	FROM
+		categories
+	WHERE
+		id = ?
+	`
+
+	var c entity.Category
+	row := tx.QueryRowContext(ctx, query, id)
+	err := row.Scan(&c.Id, &c.Name, &c.Slug, &c.Position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrCategoryNotFound
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Checking the slug has been used by other category than the exceptID.
+func (cr *categoryRepository) CheckIfSlugExists(ctx context.Context, tx *sql.Tx, slug string, exceptID uint64) (*bool, error) {
+	query := `
+		SELECT
+		EXISTS(
+		SELECT
+			id
+		FROM
+			categories
+		WHERE
+			slug = ?
+			AND id != ?
+	)
+	`
+
+	var exists bool
+	row := tx.QueryRowContext(ctx, query, slug, exceptID)
+	err := row.Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exists, nil
+}
+
+// Get the highest category position, zero when there is no category.
+func (cr *categoryRepository) MaxPosition(ctx context.Context, tx *sql.Tx) (*uint64, error) {
+	query := `
+		SELECT
+		COALESCE(MAX(position), 0)
+	FROM
+		categories
+	`
+
+	var position uint64
+	row := tx.QueryRowContext(ctx, query)
+	err := row.Scan(&position)
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// Save new category to database.
+func (cr *categoryRepository) Save(ctx context.Context, tx *sql.Tx, c entity.Category) (*entity.Category, error) {
+	query := `
+		INSERT INTO categories(name, slug, position)
+		VALUES(?, ?, ?)
+	`
+
+	result, err := tx.ExecContext(ctx, query, c.Name, c.Slug, c.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Id = uint64(id)
+
+	return &c, nil
+}
+
+// Update category name and slug.
+func (cr *categoryRepository) Update(ctx context.Context, tx *sql.Tx, c entity.Category) (*entity.Category, error) {
+	query := `
+		UPDATE
+			categories
+		SET
+			name = ?,
+			slug = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, c.Name, c.Slug, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Update position of the category.
+func (cr *categoryRepository) UpdatePosition(ctx context.Context, tx *sql.Tx, id uint64, position uint64) error {
+	query := `
+		UPDATE
+			categories
+		SET
+			position = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, position, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete category from database.
+func (cr *categoryRepository) Delete(ctx context.Context, tx *sql.Tx, id uint64) error {
+	query := `
+		DELETE
+		FROM
+			categories
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func NewRepository() CategoryRepository {
 	return &categoryRepository{}
 }
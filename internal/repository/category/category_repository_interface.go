@@ -9,4 +9,11 @@ import (
 
 type CategoryRepository interface {
 	FindAll(ctx context.Context, tx *sql.Tx) (*[]entity.Category, error)
+	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Category, error)
+	CheckIfSlugExists(ctx context.Context, tx *sql.Tx, slug string, exceptID uint64) (*bool, error)
+	MaxPosition(ctx context.Context, tx *sql.Tx) (*uint64, error)
+	Save(ctx context.Context, tx *sql.Tx, c entity.Category) (*entity.Category, error)
+	Update(ctx context.Context, tx *sql.Tx, c entity.Category) (*entity.Category, error)
+	UpdatePosition(ctx context.Context, tx *sql.Tx, id uint64, position uint64) error
+	Delete(ctx context.Context, tx *sql.Tx, id uint64) error
 }
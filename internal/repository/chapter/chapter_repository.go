@@ -3,9 +3,13 @@ package chapter
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
+	"github.com/mrizkimaulidan/storial/internal/database"
 	"github.com/mrizkimaulidan/storial/internal/entity"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type chapterRepository struct {
@@ -14,10 +18,72 @@ type chapterRepository struct {
 
 // Saving chapter likes to database.
 // Need which chapterID and userID on params.
-func (cr *chapterRepository) SaveChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) error {
+// Liking the same chapter twice is ignored.
+func (cr *chapterRepository) SaveChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64, createdAt uint64) error {
 	query := `
-		INSERT INTO chapter_likes(chapter_id, user_id)
-		VALUES(?, ?)
+		INSERT IGNORE INTO chapter_likes(chapter_id, user_id, created_at)
+		VALUES(?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, query, chapterID, userID, createdAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Saving the chapter view events to database using single insert statement.
+// The view of the chapter, story or user deleted since it was counted is
+// skipped, so it never fails the other views on the batch.
+func (cr *chapterRepository) SaveChapterViews(ctx context.Context, tx *sql.Tx, views []entity.ChapterView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	// the IDs are casted, so they are not compared as floating point
+	row := "SELECT CAST(? AS UNSIGNED) AS chapter_id, CAST(? AS UNSIGNED) AS story_id, CAST(? AS UNSIGNED) AS user_id, ? AS fingerprint, ? AS created_at"
+
+	query := fmt.Sprintf(`
+		INSERT INTO chapter_views(chapter_id, story_id, user_id, fingerprint, created_at)
+		SELECT
+			views.chapter_id,
+			views.story_id,
+			views.user_id,
+			views.fingerprint,
+			views.created_at
+		FROM
+			(%s) views
+		INNER JOIN chapters ON chapters.id = views.chapter_id
+		INNER JOIN stories ON stories.id = views.story_id
+		LEFT JOIN users ON users.id = views.user_id
+		WHERE
+			views.user_id IS NULL OR users.id IS NOT NULL
+	`, strings.TrimSuffix(strings.Repeat(row+" UNION ALL ", len(views)), " UNION ALL "))
+
+	args := make([]any, 0, len(views)*5)
+	for _, v := range views {
+		args = append(args, v.ChapterID, v.StoryID, v.UserID, v.Fingerprint, v.CreatedAt)
+	}
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Deleting chapter likes from database.
+// Unliking chapter that not liked is ignored.
+func (cr *chapterRepository) DeleteChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) error {
+	query := `
+		DELETE
+		FROM
+			chapter_likes
+		WHERE
+			chapter_id = ?
+			AND user_id = ?
 	`
 
 	_, err := tx.ExecContext(ctx, query, chapterID, userID)
@@ -28,6 +94,53 @@ func (cr *chapterRepository) SaveChapterLikes(ctx context.Context, tx *sql.Tx, c
 	return nil
 }
 
+// Checking the user has liked the chapter.
+// Returning boolean true if liked, false if not liked.
+func (cr *chapterRepository) CheckIfChapterLiked(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) (*bool, error) {
+	query := `
+		SELECT
+		EXISTS(
+		SELECT
+			id
+		FROM
+			chapter_likes
+		WHERE
+			chapter_id = ?
+			AND user_id = ?
+	)
+	`
+
+	var liked bool
+	row := tx.QueryRowContext(ctx, query, chapterID, userID)
+	err := row.Scan(&liked)
+	if err != nil {
+		return nil, err
+	}
+
+	return &liked, nil
+}
+
+// Counting comments and replies on the chapter.
+func (cr *chapterRepository) CountChapterCommentsByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		chapter_comments
+	WHERE
+		chapter_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, chapterID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
 // Counting how many chapters the user have.
 // Need the userID params.
 func (cr *chapterRepository) CountChapterByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error) {
@@ -86,6 +199,7 @@ func (cr *chapterRepository) CountChapterLikesByChapterID(ctx context.Context, t
 }
 
 // Saving chapter to database.
+// If the slug is taken concurrently, throwing an err slug taken.
 func (cr *chapterRepository) Save(ctx context.Context, tx *sql.Tx, c entity.Chapter) (*entity.Chapter, error) {
 	query := `
 		INSERT INTO chapters(
@@ -99,14 +213,23 @@ func (cr *chapterRepository) Save(ctx context.Context, tx *sql.Tx, c entity.Chap
 			reading_time,
 			is_published,
 			created_at,
-			updated_at
+			updated_at,
+			position,
+			publish_at,
+			body_format,
+			body_html,
+			published_at
 		)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := tx.ExecContext(ctx, query, c.Id, c.StoryID, c.Title, c.Slug, c.Body, c.AuthorComment, c.WordCounts,
-		c.ReadingTime, c.IsPublished, c.CreatedAt, c.UpdatedAt)
+		c.ReadingTime, c.IsPublished, c.CreatedAt, c.UpdatedAt, c.Position, c.PublishAt, c.BodyFormat, c.BodyHTML, c.PublishedAt)
 	if err != nil {
+		if database.IsDuplicateEntry(err, "chapters_story_id_slug_unique") {
+			return nil, exception.ErrSlugTaken
+		}
+
 		return nil, err
 	}
 
@@ -117,12 +240,12 @@ func (cr *chapterRepository) Save(ctx context.Context, tx *sql.Tx, c entity.Chap
 // Need userID, storySlug and chapterSlug on params.
 // Because we don't need update wrong data, that's why we need the
 // userID, storySlug, chapterSlug for validation purpose.
+// If the slug is taken concurrently, throwing an err slug taken.
 func (cr *chapterRepository) Update(ctx context.Context, tx *sql.Tx, userID uint64, storySlug string, chapterSlug string, c entity.Chapter) (*entity.Chapter, error) {
 	query := `
 		UPDATE
-		chapters,
-		stories,
-		users
+		chapters
+	INNER JOIN stories ON stories.id = chapters.story_id
 	SET
 		chapters.title = ?,
 		chapters.slug = ?,
@@ -131,14 +254,22 @@ func (cr *chapterRepository) Update(ctx context.Context, tx *sql.Tx, userID uint
 		chapters.word_counts = ?,
 		chapters.reading_time = ?,
 		chapters.is_published = ?,
-		chapters.updated_at = ?
+		chapters.updated_at = ?,
+		chapters.publish_at = ?,
+		chapters.body_format = ?,
+		chapters.body_html = ?,
+		chapters.published_at = ?
 	WHERE
 		stories.user_id = ? AND stories.slug = ? AND chapters.slug = ?
 	`
 
 	_, err := tx.ExecContext(ctx, query, c.Title, c.Slug, c.Body, c.AuthorComment, c.WordCounts, c.ReadingTime,
-		c.IsPublished, c.UpdatedAt, userID, storySlug, chapterSlug)
+		c.IsPublished, c.UpdatedAt, c.PublishAt, c.BodyFormat, c.BodyHTML, c.PublishedAt, userID, storySlug, chapterSlug)
 	if err != nil {
+		if database.IsDuplicateEntry(err, "chapters_story_id_slug_unique") {
+			return nil, exception.ErrSlugTaken
+		}
+
 		return nil, err
 	}
 
@@ -153,7 +284,19 @@ func (cr *chapterRepository) FindByStorySlugAndChapterSlug(ctx context.Context,
 		SELECT
 		chapters.*,
 		stories.*,
-		users.*
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		chapters
 	INNER JOIN stories ON chapters.story_id = stories.id
@@ -168,10 +311,66 @@ func (cr *chapterRepository) FindByStorySlugAndChapterSlug(ctx context.Context,
 	var s entity.Story
 	var c entity.Chapter
 	err := row.Scan(&c.Id, &c.StoryID, &c.Title, &c.Slug, &c.Body, &c.AuthorComment, &c.WordCounts, &c.ReadingTime, &c.IsPublished,
-		&c.CreatedAt, &c.UpdatedAt,
+		&c.CreatedAt, &c.UpdatedAt, &c.Position, &c.PublishAt, &c.BodyFormat, &c.BodyHTML, &c.PublishedAt,
 
 		&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt,
-		&s.UpdatedAt,
+		&s.UpdatedAt, &s.PublishAt,
+
+		&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
+		&u.Instagram, &u.Facebook, &u.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrChapterNotFound
+		}
+
+		return nil, err
+	}
+
+	c.User = u
+	c.Story = s
+
+	return &c, nil
+}
+
+// Find single chapter by storySlug and chapterSlug regardless the owner.
+// The visibility must be checked by the caller.
+func (cr *chapterRepository) FindBySlug(ctx context.Context, tx *sql.Tx, storySlug string, chapterSlug string) (*entity.Chapter, error) {
+	query := `
+		SELECT
+		chapters.*,
+		stories.*,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	INNER JOIN users ON stories.user_id = users.id
+	WHERE
+		stories.slug = ? AND chapters.slug = ?
+	`
+
+	row := tx.QueryRowContext(ctx, query, storySlug, chapterSlug)
+
+	var u entity.User
+	var s entity.Story
+	var c entity.Chapter
+	err := row.Scan(&c.Id, &c.StoryID, &c.Title, &c.Slug, &c.Body, &c.AuthorComment, &c.WordCounts, &c.ReadingTime, &c.IsPublished,
+		&c.CreatedAt, &c.UpdatedAt, &c.Position, &c.PublishAt, &c.BodyFormat, &c.BodyHTML, &c.PublishedAt,
+
+		&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt,
+		&s.UpdatedAt, &s.PublishAt,
 
 		&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
 		&u.Instagram, &u.Facebook, &u.CreatedAt)
@@ -219,7 +418,19 @@ func (cr *chapterRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64
 		SELECT
 		chapters.*,
 		stories.*,
-		users.*
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		chapters
 	INNER JOIN stories ON chapters.story_id = stories.id
@@ -234,10 +445,10 @@ func (cr *chapterRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64
 	var s entity.Story
 	var u entity.User
 	err := row.Scan(&c.Id, &c.StoryID, &c.Title, &c.Slug, &c.Body, &c.AuthorComment, &c.WordCounts, &c.ReadingTime,
-		&c.IsPublished, &c.CreatedAt, &c.UpdatedAt,
+		&c.IsPublished, &c.CreatedAt, &c.UpdatedAt, &c.Position, &c.PublishAt, &c.BodyFormat, &c.BodyHTML, &c.PublishedAt,
 
 		&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt,
-		&s.UpdatedAt,
+		&s.UpdatedAt, &s.PublishAt,
 
 		&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter, &u.Instagram,
 		&u.Facebook, &u.CreatedAt)
@@ -257,19 +468,21 @@ func (cr *chapterRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64
 
 // Counting chapter by storySlug.
 // This function count how many chapters the story has.
-func (cr *chapterRepository) CountChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string) (*uint64, error) {
-	query := `
+func (cr *chapterRepository) CountChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
 		SELECT
 		COUNT(*)
 	FROM
 		chapters
 	INNER JOIN stories ON chapters.story_id = stories.id
 	WHERE
-		stories.slug = ?
-	`
+		stories.slug = ? AND %s
+	`, visible)
 
 	var counts uint64
-	row := tx.QueryRowContext(ctx, query, storySlug)
+	row := tx.QueryRowContext(ctx, query, append([]any{storySlug}, visibleArgs...)...)
 
 	err := row.Scan(&counts)
 	if err != nil {
@@ -281,18 +494,22 @@ func (cr *chapterRepository) CountChapterByStorySlug(ctx context.Context, tx *sq
 
 // Find all chapters by storySlug.
 // Find all chapters related by storySlug provided on params.
-func (cr *chapterRepository) FindAllChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string) (*[]entity.Chapter, error) {
-	query := `
+func (cr *chapterRepository) FindAllChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string, viewer entity.Viewer) (*[]entity.Chapter, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
 		SELECT
 		chapters.*
 	FROM
 		chapters
 	INNER JOIN stories ON chapters.story_id = stories.id
 	WHERE
-		stories.slug = ?
-	`
+		stories.slug = ? AND %s
+	ORDER BY
+		chapters.position ASC, chapters.id ASC
+	`, visible)
 
-	rows, err := tx.QueryContext(ctx, query, storySlug)
+	rows, err := tx.QueryContext(ctx, query, append([]any{storySlug}, visibleArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -302,7 +519,7 @@ func (cr *chapterRepository) FindAllChapterByStorySlug(ctx context.Context, tx *
 	for rows.Next() {
 		var c entity.Chapter
 		err := rows.Scan(&c.Id, &c.StoryID, &c.Title, &c.Slug, &c.Body, &c.AuthorComment, &c.WordCounts, &c.ReadingTime,
-			&c.IsPublished, &c.CreatedAt, &c.UpdatedAt,
+			&c.IsPublished, &c.CreatedAt, &c.UpdatedAt, &c.Position, &c.PublishAt, &c.BodyFormat, &c.BodyHTML, &c.PublishedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -316,18 +533,30 @@ func (cr *chapterRepository) FindAllChapterByStorySlug(ctx context.Context, tx *
 
 // Find all chapters by storyID.
 // We find all chapters using storyID that provided on params.
-func (cr *chapterRepository) FindAllChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*[]entity.Chapter, error) {
-	query := `
+// The chapters are ordered by the chapter position.
+func (cr *chapterRepository) FindAllChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64, viewer entity.Viewer, page pagination.Page) (*[]entity.Chapter, error) {
+	order := pagination.Order{Value: "chapters.position", ID: "chapters.id", Desc: false}
+	visible, visibleArgs := viewer.ChapterCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		chapters.*
 	FROM
 		chapters
 	INNER JOIN stories ON chapters.story_id = stories.id
 	WHERE
-		stories.id = ?
-	`
+		stories.id = ? AND %s AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
 
-	rows, err := tx.QueryContext(ctx, query, storyID)
+	args := append([]any{storyID}, visibleArgs...)
+	args = append(args, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -337,7 +566,7 @@ func (cr *chapterRepository) FindAllChapterByStoryID(ctx context.Context, tx *sq
 	for rows.Next() {
 		var c entity.Chapter
 		err := rows.Scan(&c.Id, &c.StoryID, &c.Title, &c.Slug, &c.Body, &c.AuthorComment, &c.WordCounts, &c.ReadingTime,
-			&c.IsPublished, &c.CreatedAt, &c.UpdatedAt,
+			&c.IsPublished, &c.CreatedAt, &c.UpdatedAt, &c.Position, &c.PublishAt, &c.BodyFormat, &c.BodyHTML, &c.PublishedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -349,6 +578,562 @@ func (cr *chapterRepository) FindAllChapterByStoryID(ctx context.Context, tx *sq
 	return &chapters, nil
 }
 
+// Get the last chapter position of the story, zero when the story has no chapter.
+func (cr *chapterRepository) MaxPositionByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COALESCE(MAX(position), 0)
+	FROM
+		chapters
+	WHERE
+		story_id = ?
+	`
+
+	var position uint64
+	row := tx.QueryRowContext(ctx, query, storyID)
+	err := row.Scan(&position)
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// Update position of the chapter.
+func (cr *chapterRepository) UpdatePosition(ctx context.Context, tx *sql.Tx, id uint64, position uint64) error {
+	query := `
+		UPDATE
+			chapters
+		SET
+			position = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, position, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Move the chapters after the position one step back,
+// used to close the gap of the deleted chapter.
+func (cr *chapterRepository) ShiftPositionAfter(ctx context.Context, tx *sql.Tx, storyID uint64, position uint64) error {
+	query := `
+		UPDATE
+			chapters
+		SET
+			position = position - 1
+		WHERE
+			story_id = ?
+			AND position > ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, storyID, position)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find the chapter right before the chapter on the story order.
+// If not exists, throwing an err chapter not found.
+func (cr *chapterRepository) FindPreviousChapter(ctx context.Context, tx *sql.Tx, c entity.Chapter, viewer entity.Viewer) (*entity.Chapter, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		chapters.id,
+		chapters.title,
+		chapters.slug,
+		chapters.position
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	WHERE
+		chapters.story_id = ?
+		AND (chapters.position, chapters.id) < (?, ?)
+		AND %s
+	ORDER BY
+		chapters.position DESC, chapters.id DESC
+	LIMIT 1
+	`, visible)
+
+	return cr.findAdjacent(ctx, tx, query, c, visibleArgs)
+}
+
+// Find the chapter right after the chapter on the story order.
+// If not exists, throwing an err chapter not found.
+func (cr *chapterRepository) FindNextChapter(ctx context.Context, tx *sql.Tx, c entity.Chapter, viewer entity.Viewer) (*entity.Chapter, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		chapters.id,
+		chapters.title,
+		chapters.slug,
+		chapters.position
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	WHERE
+		chapters.story_id = ?
+		AND (chapters.position, chapters.id) > (?, ?)
+		AND %s
+	ORDER BY
+		chapters.position ASC, chapters.id ASC
+	LIMIT 1
+	`, visible)
+
+	return cr.findAdjacent(ctx, tx, query, c, visibleArgs)
+}
+
+func (cr *chapterRepository) findAdjacent(ctx context.Context, tx *sql.Tx, query string, c entity.Chapter, visibleArgs []any) (*entity.Chapter, error) {
+	var adjacent entity.Chapter
+	args := append([]any{c.StoryID, c.Position, c.Id}, visibleArgs...)
+	row := tx.QueryRowContext(ctx, query, args...)
+	err := row.Scan(&adjacent.Id, &adjacent.Title, &adjacent.Slug, &adjacent.Position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrChapterNotFound
+		}
+
+		return nil, err
+	}
+
+	adjacent.StoryID = c.StoryID
+
+	return &adjacent, nil
+}
+
+// Update the chapter content by ID.
+// If the slug is taken concurrently, throwing an err slug taken.
+func (cr *chapterRepository) UpdateContent(ctx context.Context, tx *sql.Tx, c entity.Chapter) (*entity.Chapter, error) {
+	query := `
+		UPDATE
+			chapters
+		SET
+			title = ?,
+			slug = ?,
+			body = ?,
+			author_comment = ?,
+			word_counts = ?,
+			reading_time = ?,
+			body_format = ?,
+			body_html = ?,
+			updated_at = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, c.Title, c.Slug, c.Body, c.AuthorComment, c.WordCounts, c.ReadingTime,
+		c.BodyFormat, c.BodyHTML, c.UpdatedAt, c.Id)
+	if err != nil {
+		if database.IsDuplicateEntry(err, "chapters_story_id_slug_unique") {
+			return nil, exception.ErrSlugTaken
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Save snapshot of the chapter content as a revision.
+func (cr *chapterRepository) SaveRevision(ctx context.Context, tx *sql.Tx, r entity.ChapterRevision) (*entity.ChapterRevision, error) {
+	query := `
+		INSERT INTO chapter_revisions(chapter_id, title, body, body_format, author_comment, created_at)
+		VALUES(?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.ExecContext(ctx, query, r.ChapterID, r.Title, r.Body, r.BodyFormat, r.AuthorComment, r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Id = uint64(id)
+
+	return &r, nil
+}
+
+// Find single revision of the chapter.
+// If not exists, throwing an err revision not found.
+func (cr *chapterRepository) FindRevisionByID(ctx context.Context, tx *sql.Tx, chapterID uint64, id uint64) (*entity.ChapterRevision, error) {
+	query := `
+		SELECT
+		id,
+		chapter_id,
+		title,
+		body,
+		body_format,
+		author_comment,
+		created_at
+	FROM
+		chapter_revisions
+	WHERE
+		chapter_id = ? AND id = ?
+	`
+
+	var r entity.ChapterRevision
+	row := tx.QueryRowContext(ctx, query, chapterID, id)
+	err := row.Scan(&r.Id, &r.ChapterID, &r.Title, &r.Body, &r.BodyFormat, &r.AuthorComment, &r.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrRevisionNotFound
+		}
+
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Find all revisions of the chapter ordered from the latest revision.
+// The body is not loaded, it is only needed when viewing single revision.
+func (cr *chapterRepository) FindAllRevisionByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64, page pagination.Page) (*[]entity.ChapterRevision, error) {
+	order := pagination.Order{Value: "created_at", ID: "id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		id,
+		chapter_id,
+		title,
+		author_comment,
+		created_at
+	FROM
+		chapter_revisions
+	WHERE
+		chapter_id = ? AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{chapterID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []entity.ChapterRevision
+	for rows.Next() {
+		var r entity.ChapterRevision
+		err := rows.Scan(&r.Id, &r.ChapterID, &r.Title, &r.AuthorComment, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, r)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &revisions, nil
+}
+
+// Counting revisions of the chapter.
+func (cr *chapterRepository) CountRevisionByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		chapter_revisions
+	WHERE
+		chapter_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, chapterID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting chapter by storyID.
+func (cr *chapterRepository) CountChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		COUNT(*)
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	WHERE
+		chapters.story_id = ? AND %s
+	`, visible)
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, append([]any{storyID}, visibleArgs...)...)
+
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Publish every scheduled chapter which publish time has passed.
+// Returning how many chapters has been published.
+func (cr *chapterRepository) PublishDue(ctx context.Context, tx *sql.Tx, now uint64) (*uint64, error) {
+	query := `
+		UPDATE
+			chapters
+		SET
+			is_published = 1,
+			publish_at = NULL,
+			updated_at = ?,
+			published_at = COALESCE(published_at, ?)
+		WHERE
+			is_published = 0 AND publish_at IS NOT NULL AND publish_at <= ?
+	`
+
+	result, err := tx.ExecContext(ctx, query, now, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := uint64(affected)
+
+	return &counts, nil
+}
+
+// Find the current slug of the chapter that had the slug before renamed.
+// If not exists, throwing an err chapter not found.
+func (cr *chapterRepository) FindPreviousSlug(ctx context.Context, tx *sql.Tx, storyID uint64, slug string) (*string, error) {
+	query := `
+		SELECT
+		chapters.slug
+	FROM
+		chapter_slug_histories
+	INNER JOIN chapters ON chapters.id = chapter_slug_histories.chapter_id
+	WHERE
+		chapter_slug_histories.story_id = ? AND chapter_slug_histories.slug = ?
+	`
+
+	var current string
+	row := tx.QueryRowContext(ctx, query, storyID, slug)
+	err := row.Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrChapterNotFound
+		}
+
+		return nil, err
+	}
+
+	return &current, nil
+}
+
+// Find the current and previous slugs of other chapters on the story
+// that are the base itself or the base with numeric suffix.
+func (cr *chapterRepository) FindSlugsByBase(ctx context.Context, tx *sql.Tx, storyID uint64, base string, exceptID uint64) (*[]string, error) {
+	query := `
+		SELECT
+		slug
+	FROM
+		chapters
+	WHERE
+		story_id = ?
+		AND (slug = ? OR slug LIKE ?)
+		AND id <> ?
+	UNION
+	SELECT
+		slug
+	FROM
+		chapter_slug_histories
+	WHERE
+		story_id = ?
+		AND (slug = ? OR slug LIKE ?)
+		AND chapter_id <> ?
+	`
+
+	pattern := base + "-%"
+	rows, err := tx.QueryContext(ctx, query, storyID, base, pattern, exceptID, storyID, base, pattern, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		err := rows.Scan(&slug)
+		if err != nil {
+			return nil, err
+		}
+
+		slugs = append(slugs, slug)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &slugs, nil
+}
+
+// Keep the previous slug of the renamed chapter, so the old URL still
+// refer to the chapter. The current slug is removed from the history
+// when the chapter is renamed back.
+func (cr *chapterRepository) SaveSlugHistory(ctx context.Context, tx *sql.Tx, c entity.Chapter, previousSlug string, createdAt uint64) error {
+	query := `
+		DELETE
+		FROM
+			chapter_slug_histories
+		WHERE
+			story_id = ? AND slug = ? AND chapter_id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, c.StoryID, c.Slug, c.Id)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO chapter_slug_histories(story_id, slug, chapter_id, created_at)
+		VALUES(?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			chapter_id = VALUES(chapter_id),
+			created_at = VALUES(created_at)
+	`
+
+	_, err = tx.ExecContext(ctx, query, c.StoryID, previousSlug, c.Id, createdAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find chapters ordered by ID after the given ID, only the ID, word counts,
+// reading time and body are loaded.
+func (cr *chapterRepository) FindBodiesAfterID(ctx context.Context, tx *sql.Tx, afterID uint64, limit uint64) (*[]entity.Chapter, error) {
+	query := `
+		SELECT
+		id,
+		word_counts,
+		reading_time,
+		body
+	FROM
+		chapters
+	WHERE
+		id > ?
+	ORDER BY
+		id
+	LIMIT ?
+	`
+
+	rows, err := tx.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chapters []entity.Chapter
+	for rows.Next() {
+		var c entity.Chapter
+		err := rows.Scan(&c.Id, &c.WordCounts, &c.ReadingTime, &c.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		chapters = append(chapters, c)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &chapters, nil
+}
+
+// Update word counts and reading time of the chapter,
+// the chapter is not treated as edited so updated_at is kept.
+func (cr *chapterRepository) UpdateMetrics(ctx context.Context, tx *sql.Tx, c entity.Chapter) error {
+	query := `
+		UPDATE
+			chapters
+		SET
+			word_counts = ?,
+			reading_time = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, c.WordCounts, c.ReadingTime, c.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find ID of the published chapters of the story ordered by the position,
+// so the chapters can be loaded one by one.
+func (cr *chapterRepository) FindPublishedIDsByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*[]uint64, error) {
+	query := `
+		SELECT
+		id
+	FROM
+		chapters
+	WHERE
+		story_id = ? AND is_published = 1
+	ORDER BY
+		position, id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, storyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ids, nil
+}
+
 func NewRepository() ChapterRepository {
 	return &chapterRepository{}
 }
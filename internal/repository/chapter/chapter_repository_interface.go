@@ -5,18 +5,42 @@ import (
 	"database/sql"
 
 	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type ChapterRepository interface {
 	Save(ctx context.Context, tx *sql.Tx, c entity.Chapter) (*entity.Chapter, error)
 	Update(ctx context.Context, tx *sql.Tx, userID uint64, storySlug string, chapterSlug string, c entity.Chapter) (*entity.Chapter, error)
 	FindByStorySlugAndChapterSlug(ctx context.Context, tx *sql.Tx, userID uint64, storySlug string, chapterSlug string) (*entity.Chapter, error)
+	FindBySlug(ctx context.Context, tx *sql.Tx, storySlug string, chapterSlug string) (*entity.Chapter, error)
+	FindPreviousSlug(ctx context.Context, tx *sql.Tx, storyID uint64, slug string) (*string, error)
+	FindSlugsByBase(ctx context.Context, tx *sql.Tx, storyID uint64, base string, exceptID uint64) (*[]string, error)
+	SaveSlugHistory(ctx context.Context, tx *sql.Tx, c entity.Chapter, previousSlug string, createdAt uint64) error
+	FindBodiesAfterID(ctx context.Context, tx *sql.Tx, afterID uint64, limit uint64) (*[]entity.Chapter, error)
+	UpdateMetrics(ctx context.Context, tx *sql.Tx, c entity.Chapter) error
+	FindPublishedIDsByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*[]uint64, error)
 	Delete(ctx context.Context, tx *sql.Tx, userID uint64, chapterID uint64) error
 	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Chapter, error)
-	CountChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string) (*uint64, error)
-	FindAllChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string) (*[]entity.Chapter, error)
-	FindAllChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*[]entity.Chapter, error)
+	CountChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string, viewer entity.Viewer) (*uint64, error)
+	FindAllChapterByStorySlug(ctx context.Context, tx *sql.Tx, storySlug string, viewer entity.Viewer) (*[]entity.Chapter, error)
+	FindAllChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64, viewer entity.Viewer, page pagination.Page) (*[]entity.Chapter, error)
+	CountChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64, viewer entity.Viewer) (*uint64, error)
+	MaxPositionByStoryID(ctx context.Context, tx *sql.Tx, storyID uint64) (*uint64, error)
+	UpdatePosition(ctx context.Context, tx *sql.Tx, id uint64, position uint64) error
+	ShiftPositionAfter(ctx context.Context, tx *sql.Tx, storyID uint64, position uint64) error
+	FindPreviousChapter(ctx context.Context, tx *sql.Tx, c entity.Chapter, viewer entity.Viewer) (*entity.Chapter, error)
+	FindNextChapter(ctx context.Context, tx *sql.Tx, c entity.Chapter, viewer entity.Viewer) (*entity.Chapter, error)
+	UpdateContent(ctx context.Context, tx *sql.Tx, c entity.Chapter) (*entity.Chapter, error)
+	SaveRevision(ctx context.Context, tx *sql.Tx, r entity.ChapterRevision) (*entity.ChapterRevision, error)
+	FindRevisionByID(ctx context.Context, tx *sql.Tx, chapterID uint64, id uint64) (*entity.ChapterRevision, error)
+	FindAllRevisionByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64, page pagination.Page) (*[]entity.ChapterRevision, error)
+	CountRevisionByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error)
 	CountChapterLikesByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error)
+	CountChapterCommentsByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error)
 	CountChapterByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error)
-	SaveChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) error
+	SaveChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64, createdAt uint64) error
+	SaveChapterViews(ctx context.Context, tx *sql.Tx, views []entity.ChapterView) error
+	DeleteChapterLikes(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) error
+	CheckIfChapterLiked(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) (*bool, error)
+	PublishDue(ctx context.Context, tx *sql.Tx, now uint64) (*uint64, error)
 }
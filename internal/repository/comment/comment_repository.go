@@ -0,0 +1,249 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/comment"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type commentRepository struct {
+	//
+}
+
+// Save new comment and returning it with the generated ID.
+func (cr *commentRepository) Save(ctx context.Context, tx *sql.Tx, c entity.Comment) (*entity.Comment, error) {
+	query := `
+		INSERT INTO chapter_comments(
+			chapter_id,
+			user_id,
+			parent_id,
+			body,
+			created_at,
+			updated_at
+		)
+		VALUES(?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.ExecContext(ctx, query, c.ChapterID, c.UserID, c.ParentID, c.Body, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.FindByID(ctx, tx, uint64(id))
+}
+
+// Update the comment body.
+func (cr *commentRepository) Update(ctx context.Context, tx *sql.Tx, c entity.Comment) (*entity.Comment, error) {
+	query := `
+		UPDATE
+			chapter_comments
+		SET
+			body = ?,
+			updated_at = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, c.Body, c.UpdatedAt, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return cr.FindByID(ctx, tx, c.Id)
+}
+
+// Delete the comment, the replies are deleted by foreign key cascade.
+func (cr *commentRepository) Delete(ctx context.Context, tx *sql.Tx, id uint64) error {
+	query := `
+		DELETE
+		FROM
+			chapter_comments
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find single comment by ID.
+// If the ID does not exists on database, throwing an err comment not found.
+func (cr *commentRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Comment, error) {
+	query := `
+		SELECT
+		chapter_comments.id,
+		chapter_comments.chapter_id,
+		chapter_comments.user_id,
+		chapter_comments.parent_id,
+		chapter_comments.body,
+		chapter_comments.created_at,
+		chapter_comments.updated_at,
+		users.id,
+		users.name,
+		users.username
+	FROM
+		chapter_comments
+	INNER JOIN users ON chapter_comments.user_id = users.id
+	WHERE
+		chapter_comments.id = ?
+	`
+
+	row := tx.QueryRowContext(ctx, query, id)
+
+	var c entity.Comment
+	err := row.Scan(&c.Id, &c.ChapterID, &c.UserID, &c.ParentID, &c.Body, &c.CreatedAt, &c.UpdatedAt,
+		&c.User.Id, &c.User.Name, &c.User.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrCommentNotFound
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Find top level comments of the chapter, oldest comment first.
+func (cr *commentRepository) FindAllByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64, page pagination.Page) (*[]entity.Comment, error) {
+	order := pagination.Order{Value: "chapter_comments.created_at", ID: "chapter_comments.id"}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		chapter_comments.id,
+		chapter_comments.chapter_id,
+		chapter_comments.user_id,
+		chapter_comments.parent_id,
+		chapter_comments.body,
+		chapter_comments.created_at,
+		chapter_comments.updated_at,
+		users.id,
+		users.name,
+		users.username
+	FROM
+		chapter_comments
+	INNER JOIN users ON chapter_comments.user_id = users.id
+	WHERE
+		chapter_comments.chapter_id = ?
+		AND chapter_comments.parent_id IS NULL
+		AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{chapterID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanComments(rows)
+}
+
+// Find replies of the comments, oldest reply first.
+func (cr *commentRepository) FindRepliesByParentIDs(ctx context.Context, tx *sql.Tx, parentIDs []uint64) (*[]entity.Comment, error) {
+	if len(parentIDs) == 0 {
+		return &[]entity.Comment{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(parentIDs)), ", ")
+	query := fmt.Sprintf(`
+		SELECT
+		chapter_comments.id,
+		chapter_comments.chapter_id,
+		chapter_comments.user_id,
+		chapter_comments.parent_id,
+		chapter_comments.body,
+		chapter_comments.created_at,
+		chapter_comments.updated_at,
+		users.id,
+		users.name,
+		users.username
+	FROM
+		chapter_comments
+	INNER JOIN users ON chapter_comments.user_id = users.id
+	WHERE
+		chapter_comments.parent_id IN (%s)
+	ORDER BY
+		chapter_comments.created_at ASC, chapter_comments.id ASC
+	`, placeholders)
+
+	var args []any
+	for _, id := range parentIDs {
+		args = append(args, id)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanComments(rows)
+}
+
+// Counting top level comments of the chapter, the replies are not counted.
+func (cr *commentRepository) CountCommentByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		chapter_comments
+	WHERE
+		chapter_id = ?
+		AND parent_id IS NULL
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, chapterID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+func scanComments(rows *sql.Rows) (*[]entity.Comment, error) {
+	var comments []entity.Comment
+	for rows.Next() {
+		var c entity.Comment
+		err := rows.Scan(&c.Id, &c.ChapterID, &c.UserID, &c.ParentID, &c.Body, &c.CreatedAt, &c.UpdatedAt,
+			&c.User.Id, &c.User.Name, &c.User.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, c)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &comments, nil
+}
+
+func NewRepository() CommentRepository {
+	return &commentRepository{}
+}
@@ -0,0 +1,19 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type CommentRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, c entity.Comment) (*entity.Comment, error)
+	Update(ctx context.Context, tx *sql.Tx, c entity.Comment) (*entity.Comment, error)
+	Delete(ctx context.Context, tx *sql.Tx, id uint64) error
+	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Comment, error)
+	FindAllByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64, page pagination.Page) (*[]entity.Comment, error)
+	FindRepliesByParentIDs(ctx context.Context, tx *sql.Tx, parentIDs []uint64) (*[]entity.Comment, error)
+	CountCommentByChapterID(ctx context.Context, tx *sql.Tx, chapterID uint64) (*uint64, error)
+}
@@ -0,0 +1,292 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/library"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+// Reading progress columns, the current chapter must be joined as
+// current_chapter. Word counts only include the published chapters.
+const progressColumns = `
+		reading_progress.chapter_id,
+		reading_progress.scroll_position,
+		reading_progress.updated_at,
+		current_chapter.title,
+		current_chapter.slug,
+		current_chapter.word_counts,
+		(
+			SELECT
+				COALESCE(SUM(chapters.word_counts), 0)
+			FROM
+				chapters
+			WHERE
+				chapters.story_id = reading_progress.story_id
+				AND chapters.is_published = 1
+				AND (chapters.position, chapters.id) < (current_chapter.position, current_chapter.id)
+		),
+		(
+			SELECT
+				COALESCE(SUM(chapters.word_counts), 0)
+			FROM
+				chapters
+			WHERE
+				chapters.story_id = reading_progress.story_id
+				AND chapters.is_published = 1
+		)`
+
+type libraryRepository struct {
+	//
+}
+
+// Save the reading progress, replacing the previous progress of the story.
+func (lr *libraryRepository) SaveProgress(ctx context.Context, tx *sql.Tx, p entity.ReadingProgress) error {
+	query := `
+		INSERT INTO reading_progress(user_id, story_id, chapter_id, scroll_position, updated_at)
+		VALUES(?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			chapter_id = VALUES(chapter_id),
+			scroll_position = VALUES(scroll_position),
+			updated_at = VALUES(updated_at)
+	`
+
+	_, err := tx.ExecContext(ctx, query, p.UserID, p.StoryID, p.ChapterID, p.ScrollPosition, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Save the chapters being read as the reading progress in single query.
+// The scroll position is kept when it is still the same chapter,
+// otherwise reset to the top of the chapter. The progress older than
+// the saved progress is ignored, so the buffered reads never override
+// the progress reported in the meantime.
+// The progress of the user, story or chapter deleted since it was read is
+// skipped, so it never fails the other progress on the batch.
+func (lr *libraryRepository) SaveReadChapters(ctx context.Context, tx *sql.Tx, progress []entity.ReadingProgress) error {
+	if len(progress) == 0 {
+		return nil
+	}
+
+	// the IDs are casted, so they are not compared as floating point
+	row := "SELECT CAST(? AS UNSIGNED) AS user_id, CAST(? AS UNSIGNED) AS story_id, CAST(? AS UNSIGNED) AS chapter_id, ? AS updated_at"
+
+	query := fmt.Sprintf(`
+		INSERT INTO reading_progress(user_id, story_id, chapter_id, scroll_position, updated_at)
+		SELECT
+			progress.user_id,
+			progress.story_id,
+			progress.chapter_id,
+			0,
+			progress.updated_at
+		FROM
+			(%s) progress
+		INNER JOIN users ON users.id = progress.user_id
+		INNER JOIN stories ON stories.id = progress.story_id
+		INNER JOIN chapters ON chapters.id = progress.chapter_id
+		ON DUPLICATE KEY UPDATE
+			scroll_position = IF(VALUES(updated_at) < reading_progress.updated_at OR reading_progress.chapter_id = VALUES(chapter_id), reading_progress.scroll_position, 0),
+			chapter_id = IF(VALUES(updated_at) < reading_progress.updated_at, reading_progress.chapter_id, VALUES(chapter_id)),
+			updated_at = GREATEST(reading_progress.updated_at, VALUES(updated_at))
+	`, strings.TrimSuffix(strings.Repeat(row+" UNION ALL ", len(progress)), " UNION ALL "))
+
+	args := make([]any, 0, len(progress)*4)
+	for _, p := range progress {
+		args = append(args, p.UserID, p.StoryID, p.ChapterID, p.UpdatedAt)
+	}
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find reading progress of the user on the story.
+// If not exists, throwing an err progress not found.
+func (lr *libraryRepository) FindProgress(ctx context.Context, tx *sql.Tx, userID uint64, storyID uint64) (*entity.ReadingProgress, error) {
+	query := fmt.Sprintf(`
+		SELECT
+		reading_progress.user_id,
+		reading_progress.story_id,
+		%s
+	FROM
+		reading_progress
+	INNER JOIN chapters current_chapter ON current_chapter.id = reading_progress.chapter_id
+	WHERE
+		reading_progress.user_id = ?
+		AND reading_progress.story_id = ?
+	`, progressColumns)
+
+	var p entity.ReadingProgress
+	row := tx.QueryRowContext(ctx, query, userID, storyID)
+	err := row.Scan(&p.UserID, &p.StoryID, &p.ChapterID, &p.ScrollPosition, &p.UpdatedAt,
+		&p.Chapter.Title, &p.Chapter.Slug, &p.Chapter.WordCounts, &p.PreviousWordCounts, &p.TotalWordCounts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrProgressNotFound
+		}
+
+		return nil, err
+	}
+
+	p.Chapter.Id = p.ChapterID
+	p.Chapter.StoryID = p.StoryID
+
+	return &p, nil
+}
+
+// Bookmark the story. Bookmarking the same story twice is ignored.
+func (lr *libraryRepository) SaveBookmark(ctx context.Context, tx *sql.Tx, b entity.Bookmark) error {
+	query := `
+		INSERT IGNORE INTO story_bookmarks(user_id, story_id, created_at)
+		VALUES(?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, query, b.UserID, b.StoryID, b.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Remove the story from the user bookmarks.
+func (lr *libraryRepository) DeleteBookmark(ctx context.Context, tx *sql.Tx, userID uint64, storyID uint64) error {
+	query := `
+		DELETE
+		FROM
+			story_bookmarks
+		WHERE
+			user_id = ?
+			AND story_id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, userID, storyID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find bookmarked stories of the user with the reading progress,
+// ordered by the latest bookmarked.
+func (lr *libraryRepository) FindAllBookmarkByUserID(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.Bookmark, error) {
+	order := pagination.Order{Value: "story_bookmarks.created_at", ID: "story_bookmarks.story_id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		story_bookmarks.story_id,
+		story_bookmarks.created_at,
+		stories.title,
+		stories.slug,
+		stories.is_adult,
+		users.id,
+		users.name,
+		users.username,
+		%s
+	FROM
+		story_bookmarks
+	INNER JOIN stories ON stories.id = story_bookmarks.story_id
+	INNER JOIN users ON users.id = stories.user_id
+	LEFT JOIN reading_progress ON reading_progress.user_id = story_bookmarks.user_id
+		AND reading_progress.story_id = story_bookmarks.story_id
+	LEFT JOIN chapters current_chapter ON current_chapter.id = reading_progress.chapter_id
+	WHERE
+		story_bookmarks.user_id = ?
+		AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, progressColumns, keyset, page.OrderBy(order))
+
+	args := append([]any{userID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []entity.Bookmark
+	for rows.Next() {
+		var b entity.Bookmark
+		var chapterID, scrollPosition, updatedAt, wordCounts sql.NullInt64
+		var chapterTitle, chapterSlug sql.NullString
+		var p entity.ReadingProgress
+		err := rows.Scan(&b.StoryID, &b.CreatedAt, &b.Story.Title, &b.Story.Slug, &b.Story.IsAdult,
+			&b.Story.User.Id, &b.Story.User.Name, &b.Story.User.Username,
+
+			&chapterID, &scrollPosition, &updatedAt, &chapterTitle, &chapterSlug, &wordCounts,
+			&p.PreviousWordCounts, &p.TotalWordCounts)
+		if err != nil {
+			return nil, err
+		}
+
+		b.UserID = userID
+		b.Story.Id = b.StoryID
+		b.Story.UserID = b.Story.User.Id
+
+		if chapterID.Valid {
+			p.UserID = userID
+			p.StoryID = b.StoryID
+			p.ChapterID = uint64(chapterID.Int64)
+			p.ScrollPosition = uint64(scrollPosition.Int64)
+			p.UpdatedAt = uint64(updatedAt.Int64)
+			p.Chapter = entity.Chapter{
+				Id:         p.ChapterID,
+				StoryID:    b.StoryID,
+				Title:      chapterTitle.String,
+				Slug:       chapterSlug.String,
+				WordCounts: uint64(wordCounts.Int64),
+			}
+
+			b.Progress = &p
+		}
+
+		bookmarks = append(bookmarks, b)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookmarks, nil
+}
+
+// Counting bookmarked stories of the user.
+func (lr *libraryRepository) CountBookmarkByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		story_bookmarks
+	WHERE
+		user_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, userID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+func NewRepository() LibraryRepository {
+	return &libraryRepository{}
+}
@@ -0,0 +1,19 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type LibraryRepository interface {
+	SaveProgress(ctx context.Context, tx *sql.Tx, p entity.ReadingProgress) error
+	SaveReadChapters(ctx context.Context, tx *sql.Tx, progress []entity.ReadingProgress) error
+	FindProgress(ctx context.Context, tx *sql.Tx, userID uint64, storyID uint64) (*entity.ReadingProgress, error)
+	SaveBookmark(ctx context.Context, tx *sql.Tx, b entity.Bookmark) error
+	DeleteBookmark(ctx context.Context, tx *sql.Tx, userID uint64, storyID uint64) error
+	FindAllBookmarkByUserID(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.Bookmark, error)
+	CountBookmarkByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error)
+}
@@ -0,0 +1,170 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+var (
+	TYPE_STORY   = "story"
+	TYPE_CHAPTER = "chapter"
+
+	// Position of the term not occurring in the chapter body,
+	// beyond the longest possible body.
+	NOT_FOUND = int64(1) << 32
+)
+
+type searchRepository struct {
+	//
+}
+
+// Search published stories and chapters using FULLTEXT index.
+// The hits are ordered by relevance score from the highest.
+// Adult stories and their chapters are excluded unless includeAdult is true.
+// The text of the chapter hit is only the window of the body around the first
+// occurring term, wide enough for the snippet of the radius.
+func (sr *searchRepository) Search(ctx context.Context, tx *sql.Tx, query string, terms []string, radius int, includeAdult bool, page pagination.Page) (*[]entity.SearchResult, error) {
+	window, windowArgs := bodyWindow(terms, radius)
+
+	q := fmt.Sprintf(`
+		SELECT
+		*
+	FROM
+		(
+		SELECT
+			'story' AS type,
+			stories.id AS story_id,
+			stories.title AS story_title,
+			stories.slug AS story_slug,
+			0 AS chapter_id,
+			'' AS chapter_title,
+			'' AS chapter_slug,
+			users.id AS user_id,
+			users.name AS user_name,
+			users.username AS user_username,
+			stories.description AS text,
+			MATCH(stories.title, stories.description) AGAINST(? IN NATURAL LANGUAGE MODE) AS score
+		FROM
+			stories
+		INNER JOIN users ON stories.user_id = users.id
+		WHERE
+			MATCH(stories.title, stories.description) AGAINST(? IN NATURAL LANGUAGE MODE)
+			AND stories.is_published = 1 AND (? OR stories.is_adult = 0)
+		UNION ALL
+		SELECT
+			'chapter' AS type,
+			stories.id AS story_id,
+			stories.title AS story_title,
+			stories.slug AS story_slug,
+			chapters.id AS chapter_id,
+			chapters.title AS chapter_title,
+			chapters.slug AS chapter_slug,
+			users.id AS user_id,
+			users.name AS user_name,
+			users.username AS user_username,
+			%s AS text,
+			MATCH(chapters.title, chapters.body) AGAINST(? IN NATURAL LANGUAGE MODE) AS score
+		FROM
+			chapters
+		INNER JOIN stories ON chapters.story_id = stories.id
+		INNER JOIN users ON stories.user_id = users.id
+		WHERE
+			MATCH(chapters.title, chapters.body) AGAINST(? IN NATURAL LANGUAGE MODE)
+			AND stories.is_published = 1 AND chapters.is_published = 1 AND (? OR stories.is_adult = 0)
+	) AS hits
+	ORDER BY
+		score
+	DESC
+	LIMIT ? OFFSET ?
+	`, window)
+
+	args := append([]any{query, query, includeAdult}, windowArgs...)
+	args = append(args, query, query, includeAdult, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []entity.SearchResult
+	for rows.Next() {
+		var r entity.SearchResult
+		err := rows.Scan(&r.Type, &r.Story.Id, &r.Story.Title, &r.Story.Slug, &r.Chapter.Id, &r.Chapter.Title, &r.Chapter.Slug,
+			&r.User.Id, &r.User.Name, &r.User.Username, &r.Text, &r.Score)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Story.UserID = r.User.Id
+		r.Chapter.StoryID = r.Story.Id
+		results = append(results, r)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// Counting search hits of stories and chapters.
+func (sr *searchRepository) CountSearch(ctx context.Context, tx *sql.Tx, query string, includeAdult bool) (*uint64, error) {
+	q := `
+		SELECT
+		(
+		SELECT
+			COUNT(*)
+		FROM
+			stories
+		WHERE
+			MATCH(stories.title, stories.description) AGAINST(? IN NATURAL LANGUAGE MODE)
+			AND stories.is_published = 1 AND (? OR stories.is_adult = 0)
+	) + (
+		SELECT
+			COUNT(*)
+		FROM
+			chapters
+		INNER JOIN stories ON chapters.story_id = stories.id
+		WHERE
+			MATCH(chapters.title, chapters.body) AGAINST(? IN NATURAL LANGUAGE MODE)
+			AND stories.is_published = 1 AND chapters.is_published = 1 AND (? OR stories.is_adult = 0)
+	)
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, q, query, includeAdult, query, includeAdult)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Select the window of the chapter body starting twice the radius before the
+// first occurring term, or the beginning of the body when none of the terms
+// occurs. The snippet is created without reading the whole body.
+func bodyWindow(terms []string, radius int) (string, []any) {
+	var locates []string
+	args := []any{NOT_FOUND}
+	for _, t := range terms {
+		locates = append(locates, ", IF(LOCATE(?, chapters.body) > 0, LOCATE(?, chapters.body), ?)")
+		args = append(args, t, t, NOT_FOUND)
+	}
+
+	// the first position is zero when none of the terms occurs
+	args = append(args, NOT_FOUND, radius*2, radius*5)
+
+	return fmt.Sprintf("SUBSTRING(chapters.body, GREATEST(1, MOD(LEAST(?%s), ?) - ?), ?)", strings.Join(locates, "")), args
+}
+
+func NewRepository() SearchRepository {
+	return &searchRepository{}
+}
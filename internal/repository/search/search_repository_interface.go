@@ -0,0 +1,14 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, tx *sql.Tx, query string, terms []string, radius int, includeAdult bool, page pagination.Page) (*[]entity.SearchResult, error)
+	CountSearch(ctx context.Context, tx *sql.Tx, query string, includeAdult bool) (*uint64, error)
+}
@@ -0,0 +1,342 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+)
+
+type statsRepository struct {
+	//
+}
+
+// Find statistics of every story owned by the user, ordered by the latest created.
+// Every statistic is aggregated on its own derived table, so the joins
+// do not multiply the counts of each other.
+func (sr *statsRepository) FindStoryStatsByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.StoryStats, error) {
+	query := `
+		SELECT
+		stories.id,
+		stories.title,
+		stories.slug,
+		stories.is_published,
+		COALESCE(views.views, 0),
+		COALESCE(views.readers, 0),
+		COALESCE(likes.likes, 0),
+		COALESCE(comments.comments, 0),
+		COALESCE(completion.completion, 0)
+	FROM
+		stories
+	LEFT JOIN (
+		SELECT
+			chapter_views.story_id,
+			COUNT(*) AS views,
+			COUNT(DISTINCT chapter_views.user_id) + COUNT(DISTINCT chapter_views.fingerprint) AS readers
+		FROM
+			chapter_views
+		INNER JOIN stories ON stories.id = chapter_views.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapter_views.story_id
+	) views ON views.story_id = stories.id
+	LEFT JOIN (
+		SELECT
+			chapters.story_id,
+			COUNT(*) AS likes
+		FROM
+			chapter_likes
+		INNER JOIN chapters ON chapters.id = chapter_likes.chapter_id
+		INNER JOIN stories ON stories.id = chapters.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapters.story_id
+	) likes ON likes.story_id = stories.id
+	LEFT JOIN (
+		SELECT
+			chapters.story_id,
+			COUNT(*) AS comments
+		FROM
+			chapter_comments
+		INNER JOIN chapters ON chapters.id = chapter_comments.chapter_id
+		INNER JOIN stories ON stories.id = chapters.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapters.story_id
+	) comments ON comments.story_id = stories.id
+	LEFT JOIN (
+		SELECT
+			reading_progress.story_id,
+			ROUND(AVG(LEAST(100, 100 * (
+				(
+					SELECT
+						COALESCE(SUM(chapters.word_counts), 0)
+					FROM
+						chapters
+					WHERE
+						chapters.story_id = reading_progress.story_id
+						AND chapters.is_published = 1
+						AND (chapters.position, chapters.id) < (current_chapter.position, current_chapter.id)
+				) + current_chapter.word_counts * reading_progress.scroll_position / 100
+			) / NULLIF((
+				SELECT
+					SUM(chapters.word_counts)
+				FROM
+					chapters
+				WHERE
+					chapters.story_id = reading_progress.story_id
+					AND chapters.is_published = 1
+			), 0))), 1) AS completion
+		FROM
+			reading_progress
+		INNER JOIN chapters current_chapter ON current_chapter.id = reading_progress.chapter_id
+		INNER JOIN stories ON stories.id = reading_progress.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			reading_progress.story_id
+	) completion ON completion.story_id = stories.id
+	WHERE
+		stories.user_id = ?
+	ORDER BY
+		stories.created_at DESC, stories.id DESC
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID, userID, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []entity.StoryStats
+	for rows.Next() {
+		var s entity.StoryStats
+		err := rows.Scan(&s.Story.Id, &s.Story.Title, &s.Story.Slug, &s.Story.IsPublished, &s.Views, &s.UniqueReaders,
+			&s.Likes, &s.Comments, &s.AverageCompletion)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Story.UserID = userID
+		stats = append(stats, s)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// Find statistics of every chapter on the stories owned by the user,
+// ordered by the story and the chapter position.
+// Readers who have moved past the chapter are counted as completing it,
+// readers who are still on the chapter are counted by their scroll position.
+func (sr *statsRepository) FindChapterStatsByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.ChapterStats, error) {
+	query := `
+		SELECT
+		chapters.id,
+		chapters.story_id,
+		chapters.title,
+		chapters.slug,
+		chapters.is_published,
+		chapters.position,
+		COALESCE(views.views, 0),
+		COALESCE(views.readers, 0),
+		COALESCE(likes.likes, 0),
+		COALESCE(comments.comments, 0),
+		COALESCE(completion.completion, 0)
+	FROM
+		chapters
+	INNER JOIN stories ON stories.id = chapters.story_id
+	LEFT JOIN (
+		SELECT
+			chapter_views.chapter_id,
+			COUNT(*) AS views,
+			COUNT(DISTINCT chapter_views.user_id) + COUNT(DISTINCT chapter_views.fingerprint) AS readers
+		FROM
+			chapter_views
+		INNER JOIN stories ON stories.id = chapter_views.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapter_views.chapter_id
+	) views ON views.chapter_id = chapters.id
+	LEFT JOIN (
+		SELECT
+			chapter_likes.chapter_id,
+			COUNT(*) AS likes
+		FROM
+			chapter_likes
+		INNER JOIN chapters ON chapters.id = chapter_likes.chapter_id
+		INNER JOIN stories ON stories.id = chapters.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapter_likes.chapter_id
+	) likes ON likes.chapter_id = chapters.id
+	LEFT JOIN (
+		SELECT
+			chapter_comments.chapter_id,
+			COUNT(*) AS comments
+		FROM
+			chapter_comments
+		INNER JOIN chapters ON chapters.id = chapter_comments.chapter_id
+		INNER JOIN stories ON stories.id = chapters.story_id
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapter_comments.chapter_id
+	) comments ON comments.chapter_id = chapters.id
+	LEFT JOIN (
+		SELECT
+			chapters.id AS chapter_id,
+			ROUND(AVG(
+				CASE
+					WHEN (current_chapter.position, current_chapter.id) > (chapters.position, chapters.id) THEN 100
+					ELSE reading_progress.scroll_position
+				END
+			), 1) AS completion
+		FROM
+			chapters
+		INNER JOIN stories ON stories.id = chapters.story_id
+		INNER JOIN reading_progress ON reading_progress.story_id = chapters.story_id
+		INNER JOIN chapters current_chapter ON current_chapter.id = reading_progress.chapter_id
+			AND (current_chapter.position, current_chapter.id) >= (chapters.position, chapters.id)
+		WHERE
+			stories.user_id = ?
+		GROUP BY
+			chapters.id
+	) completion ON completion.chapter_id = chapters.id
+	WHERE
+		stories.user_id = ?
+	ORDER BY
+		chapters.story_id, chapters.position, chapters.id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID, userID, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []entity.ChapterStats
+	for rows.Next() {
+		var s entity.ChapterStats
+		err := rows.Scan(&s.Chapter.Id, &s.Chapter.StoryID, &s.Chapter.Title, &s.Chapter.Slug, &s.Chapter.IsPublished,
+			&s.Chapter.Position, &s.Views, &s.UniqueReaders, &s.Likes, &s.Comments, &s.AverageCompletion)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// Find likes received per day on every story owned by the user since the given time.
+// Days without likes are not included.
+func (sr *statsRepository) FindDailyStoryLikesByUserID(ctx context.Context, tx *sql.Tx, userID uint64, since uint64) (*[]entity.DailyLikes, error) {
+	query := `
+		SELECT
+		chapters.story_id,
+		chapter_likes.created_at DIV 86400000 * 86400000 AS day,
+		COUNT(*)
+	FROM
+		chapter_likes
+	INNER JOIN chapters ON chapters.id = chapter_likes.chapter_id
+	INNER JOIN stories ON stories.id = chapters.story_id
+	WHERE
+		stories.user_id = ?
+		AND chapter_likes.created_at >= ?
+	GROUP BY
+		chapters.story_id, day
+	ORDER BY
+		chapters.story_id, day
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var likes []entity.DailyLikes
+	for rows.Next() {
+		var l entity.DailyLikes
+		err := rows.Scan(&l.StoryID, &l.Day, &l.Likes)
+		if err != nil {
+			return nil, err
+		}
+
+		likes = append(likes, l)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &likes, nil
+}
+
+// Find likes received per day on every chapter of the stories owned by the user
+// since the given time. Days without likes are not included.
+func (sr *statsRepository) FindDailyChapterLikesByUserID(ctx context.Context, tx *sql.Tx, userID uint64, since uint64) (*[]entity.DailyLikes, error) {
+	query := `
+		SELECT
+		chapters.story_id,
+		chapter_likes.chapter_id,
+		chapter_likes.created_at DIV 86400000 * 86400000 AS day,
+		COUNT(*)
+	FROM
+		chapter_likes
+	INNER JOIN chapters ON chapters.id = chapter_likes.chapter_id
+	INNER JOIN stories ON stories.id = chapters.story_id
+	WHERE
+		stories.user_id = ?
+		AND chapter_likes.created_at >= ?
+	GROUP BY
+		chapters.story_id, chapter_likes.chapter_id, day
+	ORDER BY
+		chapter_likes.chapter_id, day
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var likes []entity.DailyLikes
+	for rows.Next() {
+		var l entity.DailyLikes
+		err := rows.Scan(&l.StoryID, &l.ChapterID, &l.Day, &l.Likes)
+		if err != nil {
+			return nil, err
+		}
+
+		likes = append(likes, l)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &likes, nil
+}
+
+func NewRepository() StatsRepository {
+	return &statsRepository{}
+}
@@ -0,0 +1,15 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+)
+
+type StatsRepository interface {
+	FindStoryStatsByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.StoryStats, error)
+	FindChapterStatsByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.ChapterStats, error)
+	FindDailyStoryLikesByUserID(ctx context.Context, tx *sql.Tx, userID uint64, since uint64) (*[]entity.DailyLikes, error)
+	FindDailyChapterLikesByUserID(ctx context.Context, tx *sql.Tx, userID uint64, since uint64) (*[]entity.DailyLikes, error)
+}
@@ -3,9 +3,12 @@ package story
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
+	"github.com/mrizkimaulidan/storial/internal/database"
 	"github.com/mrizkimaulidan/storial/internal/entity"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type storyRepository struct {
@@ -13,11 +16,27 @@ type storyRepository struct {
 }
 
 // Filtering latest modified chapter by category slug.
-func (sr *storyRepository) FilterLatestModifiedChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error) {
-	query := `
+func (sr *storyRepository) FilterLatestModifiedChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "MAX(chapters.updated_at)", ID: "stories.id", Desc: true}
+	visible, visibleArgs := viewer.ChapterCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
-		users.*,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at,
 		MAX(chapters.updated_at)
 	FROM
 		stories
@@ -25,29 +44,34 @@ func (sr *storyRepository) FilterLatestModifiedChapterByCategorySlug(ctx context
 	INNER JOIN users ON stories.user_id = users.id
 	INNER JOIN categories ON stories.category_id = categories.id
 	WHERE
-		categories.slug = ?
+		categories.slug = ? AND %s
 	GROUP BY
-		chapters.story_id
+		stories.id
+	HAVING
+		%s
 	ORDER BY
-		chapters.updated_at
-	DESC
-	`
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
 
-	rows, err := tx.QueryContext(ctx, query, categorySlug)
+	args := append([]any{categorySlug}, visibleArgs...)
+	args = append(args, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	ignore := new(any)
 	var stories []entity.Story
 	for rows.Next() {
 		var s entity.Story
 		var u entity.User
-		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt,
+		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
-			&u.Instagram, &u.Facebook, &u.CreatedAt, &ignore)
+			&u.Instagram, &u.Facebook, &u.CreatedAt, &s.LatestChapterUpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -60,23 +84,43 @@ func (sr *storyRepository) FilterLatestModifiedChapterByCategorySlug(ctx context
 }
 
 // Filter latest story based category slug.
-func (sr *storyRepository) FilterLatestBasedOnCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error) {
-	query := `
+func (sr *storyRepository) FilterLatestBasedOnCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "stories.created_at", ID: "stories.id", Desc: true}
+	visible, visibleArgs := viewer.StoryCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
-		users.*
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		stories
 	INNER JOIN users ON stories.user_id = users.id
 	INNER JOIN categories ON stories.category_id = categories.id
 	WHERE
-		categories.slug = ?
+		categories.slug = ? AND %s AND %s
 	ORDER BY
-		stories.created_at
-	DESC
-	`
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
+
+	args := append([]any{categorySlug}, visibleArgs...)
+	args = append(args, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
 
-	rows, err := tx.QueryContext(ctx, query, categorySlug)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +131,7 @@ func (sr *storyRepository) FilterLatestBasedOnCategorySlug(ctx context.Context,
 		var s entity.Story
 		var u entity.User
 		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover,
-			&s.CreatedAt, &s.UpdatedAt,
+			&s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
 			&u.Instagram, &u.Facebook, &u.CreatedAt)
@@ -103,20 +147,44 @@ func (sr *storyRepository) FilterLatestBasedOnCategorySlug(ctx context.Context,
 }
 
 // Find story by category slug.
-func (sr *storyRepository) FindByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error) {
-	query := `
+// The stories are ordered by ID, so the page cursor stay stable.
+func (sr *storyRepository) FindByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "stories.id", ID: "stories.id", Desc: false}
+	visible, visibleArgs := viewer.StoryCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
-		users.*
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		stories
 	INNER JOIN users ON stories.user_id = users.id
 	INNER JOIN categories ON stories.category_id = categories.id
 	WHERE
-		categories.slug = ?
-	`
+		categories.slug = ? AND %s AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
 
-	rows, err := tx.QueryContext(ctx, query, categorySlug)
+	args := append([]any{categorySlug}, visibleArgs...)
+	args = append(args, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +195,7 @@ func (sr *storyRepository) FindByCategorySlug(ctx context.Context, tx *sql.Tx, c
 		var s entity.Story
 		var u entity.User
 		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover,
-			&s.CreatedAt, &s.UpdatedAt,
+			&s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
 			&u.Instagram, &u.Facebook, &u.CreatedAt)
@@ -142,6 +210,33 @@ func (sr *storyRepository) FindByCategorySlug(ctx context.Context, tx *sql.Tx, c
 	return &stories, nil
 }
 
+// Move every story of the category to another category.
+// Returning how many stories has been moved.
+func (sr *storyRepository) UpdateCategoryIDByCategoryID(ctx context.Context, tx *sql.Tx, fromCategoryID uint64, toCategoryID uint64) (*uint64, error) {
+	query := `
+		UPDATE
+			stories
+		SET
+			category_id = ?
+		WHERE
+			category_id = ?
+	`
+
+	result, err := tx.ExecContext(ctx, query, toCategoryID, fromCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := uint64(affected)
+
+	return &counts, nil
+}
+
 // Counting story by category ID.
 // Need the categoryID on params.
 func (sr *storyRepository) CountStoryByCategoryID(ctx context.Context, tx *sql.Tx, categoryID uint64) (*uint64, error) {
@@ -171,37 +266,60 @@ func (sr *storyRepository) CountStoryByCategoryID(ctx context.Context, tx *sql.T
 }
 
 // Filtering latest updated/modified chapter.
-func (sr *storyRepository) FilterLatestModifiedChapter(ctx context.Context, tx *sql.Tx) (*[]entity.Story, error) {
-	query := `
+func (sr *storyRepository) FilterLatestModifiedChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "MAX(chapters.updated_at)", ID: "stories.id", Desc: true}
+	visible, visibleArgs := viewer.ChapterCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
-		users.*,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at,
 		MAX(chapters.updated_at)
 	FROM
 		stories
 	JOIN chapters ON chapters.story_id = stories.id
 	JOIN users ON stories.user_id = users.id
+	WHERE
+		%s
 	GROUP BY
-		chapters.story_id
+		stories.id
+	HAVING
+		%s
 	ORDER BY
-		chapters.updated_at
-	DESC
-	`
-	rows, err := tx.QueryContext(ctx, query)
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
+
+	args := append(visibleArgs, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	ignore := new(any)
 	var stories []entity.Story
 	for rows.Next() {
 		var s entity.Story
 		var u entity.User
-		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt,
+		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
-			&u.Instagram, &u.Facebook, &u.CreatedAt, &ignore)
+			&u.Instagram, &u.Facebook, &u.CreatedAt, &s.LatestChapterUpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -214,20 +332,41 @@ func (sr *storyRepository) FilterLatestModifiedChapter(ctx context.Context, tx *
 }
 
 // Filter latest created story.
-func (sr *storyRepository) FilterLatest(ctx context.Context, tx *sql.Tx) (*[]entity.Story, error) {
-	query := `
+func (sr *storyRepository) FilterLatest(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "stories.created_at", ID: "stories.id", Desc: true}
+	visible, visibleArgs := viewer.StoryCondition()
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
-		users.*
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		stories
 	INNER JOIN users ON stories.user_id = users.id
+	WHERE
+		%s AND %s
 	ORDER BY
-		stories.created_at
-	DESC
-	`
+		%s
+	LIMIT ? OFFSET ?
+	`, visible, keyset, page.OrderBy(order))
 
-	rows, err := tx.QueryContext(ctx, query)
+	args := append(visibleArgs, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +378,7 @@ func (sr *storyRepository) FilterLatest(ctx context.Context, tx *sql.Tx) (*[]ent
 		var u entity.User
 
 		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover,
-			&s.CreatedAt, &s.UpdatedAt,
+			&s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
 			&u.Instagram, &u.Facebook, &u.CreatedAt)
@@ -258,8 +397,11 @@ func (sr *storyRepository) FilterLatest(ctx context.Context, tx *sql.Tx) (*[]ent
 // We get all the story based user that has the story.
 // We don't need to shows another story that the story does not owned by userID provided.
 // That's why we need the userID on params.
-func (sr *storyRepository) FindAllByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.Story, error) {
-	query := `
+func (sr *storyRepository) FindAllByUserID(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "stories.created_at", ID: "stories.id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
 		SELECT
 		stories.*,
 		users.id,
@@ -269,10 +411,16 @@ func (sr *storyRepository) FindAllByUserID(ctx context.Context, tx *sql.Tx, user
 		stories
 	INNER JOIN users ON stories.user_id = users.id
 	WHERE
-		user_id = ?
-	`
+		user_id = ? AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{userID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
 
-	rows, err := tx.QueryContext(ctx, query, userID)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +431,7 @@ func (sr *storyRepository) FindAllByUserID(ctx context.Context, tx *sql.Tx, user
 		var u entity.User
 		var s entity.Story
 		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover,
-			&s.CreatedAt, &s.UpdatedAt,
+			&s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Email)
 		if err != nil {
@@ -297,6 +445,123 @@ func (sr *storyRepository) FindAllByUserID(ctx context.Context, tx *sql.Tx, user
 	return &stories, nil
 }
 
+// Counting all stories.
+func (sr *storyRepository) CountStory(ctx context.Context, tx *sql.Tx, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.StoryCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		COUNT(*)
+	FROM
+		stories
+	WHERE
+		%s
+	`, visible)
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, visibleArgs...)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting stories that has at least one chapter.
+func (sr *storyRepository) CountStoryWithChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		COUNT(DISTINCT chapters.story_id)
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	WHERE
+		%s
+	`, visible)
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, visibleArgs...)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting stories by category slug.
+func (sr *storyRepository) CountStoryByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.StoryCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		COUNT(*)
+	FROM
+		stories
+	INNER JOIN categories ON stories.category_id = categories.id
+	WHERE
+		categories.slug = ? AND %s
+	`, visible)
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, append([]any{categorySlug}, visibleArgs...)...)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting stories that has at least one chapter by category slug.
+func (sr *storyRepository) CountStoryWithChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer) (*uint64, error) {
+	visible, visibleArgs := viewer.ChapterCondition()
+
+	query := fmt.Sprintf(`
+		SELECT
+		COUNT(DISTINCT chapters.story_id)
+	FROM
+		chapters
+	INNER JOIN stories ON chapters.story_id = stories.id
+	INNER JOIN categories ON stories.category_id = categories.id
+	WHERE
+		categories.slug = ? AND %s
+	`, visible)
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, append([]any{categorySlug}, visibleArgs...)...)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting stories owned by the userID.
+func (sr *storyRepository) CountStoryByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		stories
+	WHERE
+		user_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, userID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
 // Find single story by ID.
 // If the ID does not exists on database, throwing an err story not found.
 func (sr *storyRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Story, error) {
@@ -313,7 +578,7 @@ func (sr *storyRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64)
 	row := tx.QueryRowContext(ctx, query, id)
 
 	err := row.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover,
-		&s.CreatedAt, &s.UpdatedAt)
+		&s.CreatedAt, &s.UpdatedAt, &s.PublishAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, exception.ErrStoryNotFound
@@ -330,6 +595,34 @@ func (sr *storyRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64)
 	return story, nil
 }
 
+// Lock single story row by ID until the transaction ends, so the chapters
+// of the story are not positioned concurrently.
+// If the ID does not exists on database, throwing an err story not found.
+func (sr *storyRepository) LockByID(ctx context.Context, tx *sql.Tx, id uint64) error {
+	query := `
+		SELECT
+		id
+	FROM
+		stories
+	WHERE
+		id = ?
+	FOR UPDATE
+	`
+
+	var locked uint64
+	row := tx.QueryRowContext(ctx, query, id)
+	err := row.Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return exception.ErrStoryNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 // Delete single story by ID.
 func (sr *storyRepository) Delete(ctx context.Context, tx *sql.Tx, id uint64) error {
 	query := `
@@ -354,8 +647,22 @@ func (sr *storyRepository) FindBySlugAndUserID(ctx context.Context, tx *sql.Tx,
 	query := `
 		SELECT
 		stories.*,
-		categories.*,
-		users.*
+		categories.id,
+		categories.name,
+		categories.slug,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		stories
 	INNER JOIN categories ON stories.category_id = categories.id
@@ -370,7 +677,7 @@ func (sr *storyRepository) FindBySlugAndUserID(ctx context.Context, tx *sql.Tx,
 	var c entity.Category
 	var u entity.User
 	err := row.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult,
-		&s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt,
+		&s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 		&c.Id, &c.Name, &c.Slug,
 
@@ -390,6 +697,7 @@ func (sr *storyRepository) FindBySlugAndUserID(ctx context.Context, tx *sql.Tx,
 }
 
 // Updating single story by slug.
+// If the slug is taken concurrently, throwing an err slug taken.
 func (sr *storyRepository) Update(ctx context.Context, tx *sql.Tx, slug string, s entity.Story) (*entity.Story, error) {
 	query := `
 		UPDATE
@@ -403,13 +711,18 @@ func (sr *storyRepository) Update(ctx context.Context, tx *sql.Tx, slug string,
 		is_adult = ?,
 		is_published = ?,
 		cover = ?,
-		updated_at = ?
+		updated_at = ?,
+		publish_at = ?
 	WHERE
 		slug = ? AND user_id = ?
 	`
 	_, err := tx.ExecContext(ctx, query, s.UserID, s.CategoryID, s.Title, s.Slug, s.Description, s.IsAdult, s.IsPublished,
-		s.Cover, s.UpdatedAt, slug, s.UserID)
+		s.Cover, s.UpdatedAt, s.PublishAt, slug, s.UserID)
 	if err != nil {
+		if database.IsDuplicateEntry(err, "stories_slug_unique") {
+			return nil, exception.ErrSlugTaken
+		}
+
 		return nil, err
 	}
 
@@ -422,6 +735,7 @@ func (sr *storyRepository) Update(ctx context.Context, tx *sql.Tx, slug string,
 }
 
 // Saving story into database.
+// If the slug is taken concurrently, throwing an err slug taken.
 func (sr *storyRepository) Save(ctx context.Context, tx *sql.Tx, s entity.Story) (*entity.Story, error) {
 	query := `
 		INSERT INTO stories(
@@ -435,7 +749,8 @@ func (sr *storyRepository) Save(ctx context.Context, tx *sql.Tx, s entity.Story)
 			is_published,
 			cover,
 			created_at,
-			updated_at
+			updated_at,
+			publish_at
 		)
 		VALUES(
 			?,
@@ -448,13 +763,18 @@ func (sr *storyRepository) Save(ctx context.Context, tx *sql.Tx, s entity.Story)
 			?,
 			?,
 			?,
+			?,
 			?
 		)
 	`
 
 	_, err := tx.ExecContext(ctx, query, s.Id, s.UserID, s.CategoryID, s.Title, s.Slug, s.Description, s.IsAdult,
-		s.IsPublished, s.Cover, s.CreatedAt, s.UpdatedAt)
+		s.IsPublished, s.Cover, s.CreatedAt, s.UpdatedAt, s.PublishAt)
 	if err != nil {
+		if database.IsDuplicateEntry(err, "stories_slug_unique") {
+			return nil, exception.ErrSlugTaken
+		}
+
 		return nil, err
 	}
 
@@ -474,7 +794,9 @@ func (sr *storyRepository) load(ctx context.Context, tx *sql.Tx, storyID uint64)
 		users.id,
 		users.name,
 		users.email,
-		categories.*
+		categories.id,
+		categories.name,
+		categories.slug
 	FROM
 		stories
 	INNER JOIN categories ON stories.category_id = categories.id
@@ -494,7 +816,7 @@ func (sr *storyRepository) load(ctx context.Context, tx *sql.Tx, storyID uint64)
 	var c entity.Category
 	for row.Next() {
 		err := row.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult,
-			&s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt,
+			&s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 			&u.Id, &u.Name, &u.Email,
 
@@ -515,8 +837,22 @@ func (sr *storyRepository) FindBySlug(ctx context.Context, tx *sql.Tx, slug stri
 	query := `
 		SELECT
 		stories.*,
-		categories.*,
-		users.*
+		categories.id,
+		categories.name,
+		categories.slug,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at
 	FROM
 		stories
 	INNER JOIN categories ON stories.category_id = categories.id
@@ -530,7 +866,7 @@ func (sr *storyRepository) FindBySlug(ctx context.Context, tx *sql.Tx, slug stri
 	var u entity.User
 	var c entity.Category
 	err := row.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished,
-		&s.Cover, &s.CreatedAt, &s.UpdatedAt,
+		&s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
 
 		&c.Id, &c.Name, &c.Slug,
 
@@ -550,6 +886,280 @@ func (sr *storyRepository) FindBySlug(ctx context.Context, tx *sql.Tx, slug stri
 	return &s, nil
 }
 
+// Filter stories from authors followed by the user, ordered by the latest
+// published chapter. Only published stories and chapters are included.
+// Adult stories are excluded unless includeAdult is true.
+func (sr *storyRepository) FilterLatestPublishedChapterByFollowerID(ctx context.Context, tx *sql.Tx, followerID uint64, includeAdult bool, page pagination.Page) (*[]entity.Story, error) {
+	order := pagination.Order{Value: "MAX(chapters.published_at)", ID: "stories.id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		stories.*,
+		users.id,
+		users.name,
+		users.username,
+		users.email,
+		users.password,
+		users.sex,
+		users.bio,
+		users.date_of_birth,
+		users.phone_number,
+		users.twitter,
+		users.instagram,
+		users.facebook,
+		users.created_at,
+		MAX(chapters.published_at)
+	FROM
+		stories
+	JOIN user_follows ON user_follows.followee_id = stories.user_id
+	JOIN chapters ON chapters.story_id = stories.id
+	JOIN users ON stories.user_id = users.id
+	WHERE
+		user_follows.follower_id = ?
+		AND stories.is_published = 1
+		AND chapters.is_published = 1
+		AND (? OR stories.is_adult = 0)
+	GROUP BY
+		stories.id
+	HAVING
+		%s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{followerID, includeAdult}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []entity.Story
+	for rows.Next() {
+		var s entity.Story
+		var u entity.User
+		err := rows.Scan(&s.Id, &s.UserID, &s.CategoryID, &s.Title, &s.Slug, &s.Description, &s.IsAdult, &s.IsPublished, &s.Cover, &s.CreatedAt, &s.UpdatedAt, &s.PublishAt,
+
+			&u.Id, &u.Name, &u.Username, &u.Email, &u.Password, &u.Sex, &u.Bio, &u.DateOfBirth, &u.PhoneNumber, &u.Twitter,
+			&u.Instagram, &u.Facebook, &u.CreatedAt, &s.LatestChapterPublishedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		s.User = u
+		stories = append(stories, s)
+	}
+
+	return &stories, nil
+}
+
+// Counting published stories with published chapter from authors followed by the user.
+// Adult stories are excluded unless includeAdult is true.
+func (sr *storyRepository) CountStoryWithPublishedChapterByFollowerID(ctx context.Context, tx *sql.Tx, followerID uint64, includeAdult bool) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(DISTINCT stories.id)
+	FROM
+		stories
+	JOIN user_follows ON user_follows.followee_id = stories.user_id
+	JOIN chapters ON chapters.story_id = stories.id
+	WHERE
+		user_follows.follower_id = ?
+		AND stories.is_published = 1
+		AND chapters.is_published = 1
+		AND (? OR stories.is_adult = 0)
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, followerID, includeAdult)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Publish every scheduled story which publish time has passed.
+// Returning how many stories has been published.
+func (sr *storyRepository) PublishDue(ctx context.Context, tx *sql.Tx, now uint64) (*uint64, error) {
+	query := `
+		UPDATE
+			stories
+		SET
+			is_published = 1,
+			publish_at = NULL,
+			updated_at = ?
+		WHERE
+			is_published = 0 AND publish_at IS NOT NULL AND publish_at <= ?
+	`
+
+	result, err := tx.ExecContext(ctx, query, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := uint64(affected)
+
+	return &counts, nil
+}
+
+// Find the story by the slug it had before renamed.
+// If not exists, throwing an err story not found.
+func (sr *storyRepository) FindByPreviousSlug(ctx context.Context, tx *sql.Tx, slug string) (*entity.Story, error) {
+	query := `
+		SELECT
+		story_id
+	FROM
+		story_slug_histories
+	WHERE
+		slug = ?
+	`
+
+	var storyID uint64
+	row := tx.QueryRowContext(ctx, query, slug)
+	err := row.Scan(&storyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrStoryNotFound
+		}
+
+		return nil, err
+	}
+
+	return sr.FindByID(ctx, tx, storyID)
+}
+
+// Find the current and previous slugs of other stories that are the base
+// itself or the base with numeric suffix.
+func (sr *storyRepository) FindSlugsByBase(ctx context.Context, tx *sql.Tx, base string, exceptID uint64) (*[]string, error) {
+	query := `
+		SELECT
+		slug
+	FROM
+		stories
+	WHERE
+		(slug = ? OR slug LIKE ?)
+		AND id <> ?
+	UNION
+	SELECT
+		slug
+	FROM
+		story_slug_histories
+	WHERE
+		(slug = ? OR slug LIKE ?)
+		AND story_id <> ?
+	`
+
+	pattern := base + "-%"
+	rows, err := tx.QueryContext(ctx, query, base, pattern, exceptID, base, pattern, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		err := rows.Scan(&slug)
+		if err != nil {
+			return nil, err
+		}
+
+		slugs = append(slugs, slug)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &slugs, nil
+}
+
+// Keep the previous slug of the renamed story, so the old URL still
+// refer to the story. The current slug is removed from the history
+// when the story is renamed back.
+func (sr *storyRepository) SaveSlugHistory(ctx context.Context, tx *sql.Tx, storyID uint64, previousSlug string, slug string, createdAt uint64) error {
+	query := `
+		DELETE
+		FROM
+			story_slug_histories
+		WHERE
+			slug = ? AND story_id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, slug, storyID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO story_slug_histories(slug, story_id, created_at)
+		VALUES(?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			story_id = VALUES(story_id),
+			created_at = VALUES(created_at)
+	`
+
+	_, err = tx.ExecContext(ctx, query, previousSlug, storyID, createdAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find stories that have cover ordered by ID after the given ID,
+// only the ID and cover are loaded.
+func (sr *storyRepository) FindCoversAfterID(ctx context.Context, tx *sql.Tx, afterID uint64, limit uint64) (*[]entity.Story, error) {
+	query := `
+		SELECT
+		id,
+		cover
+	FROM
+		stories
+	WHERE
+		id > ? AND cover != ''
+	ORDER BY
+		id
+	LIMIT ?
+	`
+
+	rows, err := tx.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []entity.Story
+	for rows.Next() {
+		var s entity.Story
+		err := rows.Scan(&s.Id, &s.Cover)
+		if err != nil {
+			return nil, err
+		}
+
+		stories = append(stories, s)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &stories, nil
+}
+
 func NewRepository() StoryRepository {
 	return &storyRepository{}
 }
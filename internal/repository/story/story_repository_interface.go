@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type StoryRepository interface {
@@ -12,13 +13,27 @@ type StoryRepository interface {
 	Update(ctx context.Context, tx *sql.Tx, slug string, s entity.Story) (*entity.Story, error)
 	FindBySlugAndUserID(ctx context.Context, tx *sql.Tx, slug string, userID uint64) (*entity.Story, error)
 	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.Story, error)
+	LockByID(ctx context.Context, tx *sql.Tx, id uint64) error
 	Delete(ctx context.Context, tx *sql.Tx, id uint64) error
-	FindAllByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*[]entity.Story, error)
+	FindAllByUserID(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.Story, error)
 	FindBySlug(ctx context.Context, tx *sql.Tx, slug string) (*entity.Story, error)
-	FilterLatest(ctx context.Context, tx *sql.Tx) (*[]entity.Story, error)
-	FilterLatestModifiedChapter(ctx context.Context, tx *sql.Tx) (*[]entity.Story, error)
+	FindByPreviousSlug(ctx context.Context, tx *sql.Tx, slug string) (*entity.Story, error)
+	FindSlugsByBase(ctx context.Context, tx *sql.Tx, base string, exceptID uint64) (*[]string, error)
+	SaveSlugHistory(ctx context.Context, tx *sql.Tx, storyID uint64, previousSlug string, slug string, createdAt uint64) error
+	FilterLatest(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error)
+	FilterLatestModifiedChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error)
 	CountStoryByCategoryID(ctx context.Context, tx *sql.Tx, categoryID uint64) (*uint64, error)
-	FindByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error)
-	FilterLatestBasedOnCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error)
-	FilterLatestModifiedChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string) (*[]entity.Story, error)
+	UpdateCategoryIDByCategoryID(ctx context.Context, tx *sql.Tx, fromCategoryID uint64, toCategoryID uint64) (*uint64, error)
+	FindByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error)
+	FilterLatestBasedOnCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error)
+	FilterLatestModifiedChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer, page pagination.Page) (*[]entity.Story, error)
+	CountStory(ctx context.Context, tx *sql.Tx, viewer entity.Viewer) (*uint64, error)
+	CountStoryWithChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer) (*uint64, error)
+	CountStoryByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer) (*uint64, error)
+	CountStoryWithChapterByCategorySlug(ctx context.Context, tx *sql.Tx, categorySlug string, viewer entity.Viewer) (*uint64, error)
+	CountStoryByUserID(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error)
+	FilterLatestPublishedChapterByFollowerID(ctx context.Context, tx *sql.Tx, followerID uint64, includeAdult bool, page pagination.Page) (*[]entity.Story, error)
+	CountStoryWithPublishedChapterByFollowerID(ctx context.Context, tx *sql.Tx, followerID uint64, includeAdult bool) (*uint64, error)
+	PublishDue(ctx context.Context, tx *sql.Tx, now uint64) (*uint64, error)
+	FindCoversAfterID(ctx context.Context, tx *sql.Tx, afterID uint64, limit uint64) (*[]entity.Story, error)
 }
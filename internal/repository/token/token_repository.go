@@ -0,0 +1,182 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/authentication"
+)
+
+type tokenRepository struct {
+	//
+}
+
+// Saving refresh token to database.
+// Only the token hashes are saved, never the plain token.
+func (tr *tokenRepository) SaveRefreshToken(ctx context.Context, tx *sql.Tx, rt entity.RefreshToken) (*entity.RefreshToken, error) {
+	query := `
+		INSERT INTO refresh_tokens(
+			user_id,
+			token_hash,
+			expires_at,
+			created_at
+		)
+		VALUES(?, ?, ?, ?)
+	`
+
+	result, err := tx.ExecContext(ctx, query, rt.UserID, rt.TokenHash, rt.ExpiresAt, rt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	rt.Id = uint64(id)
+
+	return &rt, nil
+}
+
+// Find single refresh token by hashes.
+// The row is locked until the transaction ends, so the same refresh token
+// cannot be rotated twice concurrently.
+func (tr *tokenRepository) FindRefreshTokenByHash(ctx context.Context, tx *sql.Tx, hash string) (*entity.RefreshToken, error) {
+	query := `
+		SELECT
+		id,
+		user_id,
+		token_hash,
+		expires_at,
+		revoked_at,
+		replaced_by,
+		created_at
+	FROM
+		refresh_tokens
+	WHERE
+		token_hash = ?
+	FOR UPDATE
+	`
+
+	row := tx.QueryRowContext(ctx, query, hash)
+
+	var rt entity.RefreshToken
+	err := row.Scan(&rt.Id, &rt.UserID, &rt.TokenHash, &rt.ExpiresAt, &rt.RevokedAt, &rt.ReplacedBy, &rt.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrRefreshTokenInvalid
+		}
+
+		return nil, err
+	}
+
+	return &rt, nil
+}
+
+// Revoke single refresh token by ID.
+// The replacedBy is the ID of the new refresh token when the token is rotated.
+func (tr *tokenRepository) RevokeRefreshToken(ctx context.Context, tx *sql.Tx, id uint64, replacedBy *uint64, revokedAt uint64) error {
+	query := `
+		UPDATE
+		refresh_tokens
+	SET
+		revoked_at = ?,
+		replaced_by = ?
+	WHERE
+		id = ? AND revoked_at IS NULL
+	`
+
+	_, err := tx.ExecContext(ctx, query, revokedAt, replacedBy, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Revoke all active refresh token owned by the userID.
+func (tr *tokenRepository) RevokeAllRefreshTokenByUserID(ctx context.Context, tx *sql.Tx, userID uint64, revokedAt uint64) error {
+	query := `
+		UPDATE
+		refresh_tokens
+	SET
+		revoked_at = ?
+	WHERE
+		user_id = ? AND revoked_at IS NULL
+	`
+
+	_, err := tx.ExecContext(ctx, query, revokedAt, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Saving revoked access token ID (jti) to database.
+// The expiresAt is the access token expiration, after that the row is no longer needed.
+func (tr *tokenRepository) SaveRevokedAccessToken(ctx context.Context, tx *sql.Tx, jti string, expiresAt uint64) error {
+	query := `
+		INSERT IGNORE INTO revoked_tokens(jti, expires_at)
+		VALUES(?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, query, jti, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Checking access token ID (jti) if already revoked.
+// Returning boolean true if revoked, false if not revoked.
+// Checked on every authorized request, so it's queried without transaction.
+func (tr *tokenRepository) CheckIfAccessTokenRevoked(ctx context.Context, db *sql.DB, jti string) (*bool, error) {
+	query := `
+		SELECT
+		EXISTS(
+		SELECT
+			jti
+		FROM
+			revoked_tokens
+		WHERE
+			jti = ?
+	)
+	`
+
+	row := db.QueryRowContext(ctx, query, jti)
+
+	var exists bool
+	err := row.Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exists, nil
+}
+
+// Delete revoked access token that already expired based on n unix timestamp.
+// Expired access token will be rejected anyway, no need to keep them.
+func (tr *tokenRepository) DeleteExpiredRevokedAccessToken(ctx context.Context, tx *sql.Tx, n uint64) error {
+	query := `
+		DELETE
+		FROM
+			revoked_tokens
+		WHERE
+			expires_at < ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, n)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewRepository() TokenRepository {
+	return &tokenRepository{}
+}
@@ -0,0 +1,18 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+)
+
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, tx *sql.Tx, rt entity.RefreshToken) (*entity.RefreshToken, error)
+	FindRefreshTokenByHash(ctx context.Context, tx *sql.Tx, hash string) (*entity.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tx *sql.Tx, id uint64, replacedBy *uint64, revokedAt uint64) error
+	RevokeAllRefreshTokenByUserID(ctx context.Context, tx *sql.Tx, userID uint64, revokedAt uint64) error
+	SaveRevokedAccessToken(ctx context.Context, tx *sql.Tx, jti string, expiresAt uint64) error
+	CheckIfAccessTokenRevoked(ctx context.Context, db *sql.DB, jti string) (*bool, error)
+	DeleteExpiredRevokedAccessToken(ctx context.Context, tx *sql.Tx, n uint64) error
+}
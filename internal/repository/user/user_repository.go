@@ -0,0 +1,335 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/user"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type userRepository struct {
+	//
+}
+
+// Find single user by ID.
+// If the ID does not exists on database, throwing an err user not found.
+func (ur *userRepository) FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.User, error) {
+	query := `
+		SELECT
+		id,
+		name,
+		username,
+		email,
+		sex,
+		role,
+		bio,
+		date_of_birth,
+		phone_number,
+		twitter,
+		instagram,
+		facebook,
+		created_at,
+		show_adult_content
+	FROM
+		users
+	WHERE
+		id = ?
+	`
+
+	row := tx.QueryRowContext(ctx, query, id)
+
+	return scanUser(row)
+}
+
+// Find single user by username.
+// If the username does not exists on database, throwing an err user not found.
+func (ur *userRepository) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (*entity.User, error) {
+	query := `
+		SELECT
+		id,
+		name,
+		username,
+		email,
+		sex,
+		role,
+		bio,
+		date_of_birth,
+		phone_number,
+		twitter,
+		instagram,
+		facebook,
+		created_at,
+		show_adult_content
+	FROM
+		users
+	WHERE
+		username = ?
+	`
+
+	row := tx.QueryRowContext(ctx, query, username)
+
+	return scanUser(row)
+}
+
+// Update the profile fields of the user.
+// Username, email and password are not changed.
+func (ur *userRepository) UpdateProfile(ctx context.Context, tx *sql.Tx, u entity.User) (*entity.User, error) {
+	query := `
+		UPDATE
+			users
+		SET
+			name = ?,
+			sex = ?,
+			bio = ?,
+			date_of_birth = ?,
+			phone_number = ?,
+			twitter = ?,
+			instagram = ?,
+			facebook = ?,
+			show_adult_content = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, u.Name, u.Sex, u.Bio, u.DateOfBirth, u.PhoneNumber, u.Twitter, u.Instagram, u.Facebook,
+		u.ShowAdultContent, u.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ur.FindByID(ctx, tx, u.Id)
+}
+
+// Update the role of the user.
+func (ur *userRepository) UpdateRole(ctx context.Context, tx *sql.Tx, id uint64, role string) error {
+	query := `
+		UPDATE
+			users
+		SET
+			role = ?
+		WHERE
+			id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, role, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Save the follow relation. Following the same user twice is ignored.
+func (ur *userRepository) Follow(ctx context.Context, tx *sql.Tx, f entity.UserFollow) error {
+	query := `
+		INSERT IGNORE INTO user_follows(
+			follower_id,
+			followee_id,
+			created_at
+		)
+		VALUES(?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, query, f.FollowerID, f.FolloweeID, f.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete the follow relation. Unfollowing user that not followed is ignored.
+func (ur *userRepository) Unfollow(ctx context.Context, tx *sql.Tx, followerID uint64, followeeID uint64) error {
+	query := `
+		DELETE
+		FROM
+			user_follows
+		WHERE
+			follower_id = ?
+			AND followee_id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, followerID, followeeID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find users who follow the user, latest follow first.
+func (ur *userRepository) FindFollowers(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.UserFollow, error) {
+	order := pagination.Order{Value: "user_follows.created_at", ID: "user_follows.follower_id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		user_follows.follower_id,
+		user_follows.followee_id,
+		user_follows.created_at,
+		users.id,
+		users.name,
+		users.username
+	FROM
+		user_follows
+	INNER JOIN users ON user_follows.follower_id = users.id
+	WHERE
+		user_follows.followee_id = ?
+		AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{userID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []entity.UserFollow
+	for rows.Next() {
+		var f entity.UserFollow
+		err := rows.Scan(&f.FollowerID, &f.FolloweeID, &f.CreatedAt, &f.Follower.Id, &f.Follower.Name, &f.Follower.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, f)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &follows, nil
+}
+
+// Find users followed by the user, latest follow first.
+func (ur *userRepository) FindFollowing(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.UserFollow, error) {
+	order := pagination.Order{Value: "user_follows.created_at", ID: "user_follows.followee_id", Desc: true}
+	keyset, keysetArgs := page.Keyset(order)
+
+	query := fmt.Sprintf(`
+		SELECT
+		user_follows.follower_id,
+		user_follows.followee_id,
+		user_follows.created_at,
+		users.id,
+		users.name,
+		users.username
+	FROM
+		user_follows
+	INNER JOIN users ON user_follows.followee_id = users.id
+	WHERE
+		user_follows.follower_id = ?
+		AND %s
+	ORDER BY
+		%s
+	LIMIT ? OFFSET ?
+	`, keyset, page.OrderBy(order))
+
+	args := append([]any{userID}, keysetArgs...)
+	args = append(args, page.Fetch(), page.Offset())
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []entity.UserFollow
+	for rows.Next() {
+		var f entity.UserFollow
+		err := rows.Scan(&f.FollowerID, &f.FolloweeID, &f.CreatedAt, &f.Followee.Id, &f.Followee.Name, &f.Followee.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, f)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return &follows, nil
+}
+
+// Counting how many users follow the user.
+func (ur *userRepository) CountFollowers(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		user_follows
+	WHERE
+		followee_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, userID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Counting how many users followed by the user.
+func (ur *userRepository) CountFollowing(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error) {
+	query := `
+		SELECT
+		COUNT(*)
+	FROM
+		user_follows
+	WHERE
+		follower_id = ?
+	`
+
+	var counts uint64
+	row := tx.QueryRowContext(ctx, query, userID)
+	err := row.Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// Scan single user row with the profile fields.
+func scanUser(row *sql.Row) (*entity.User, error) {
+	var user entity.User
+	var bio, phoneNumber, twitter, instagram, facebook sql.NullString
+	var dateOfBirth sql.NullInt64
+	err := row.Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Sex, &user.Role, &bio, &dateOfBirth, &phoneNumber, &twitter, &instagram, &facebook, &user.CreatedAt,
+		&user.ShowAdultContent)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.Bio = &bio
+	user.DateOfBirth = &dateOfBirth
+	user.PhoneNumber = &phoneNumber
+	user.Twitter = &twitter
+	user.Instagram = &instagram
+	user.Facebook = &facebook
+
+	return &user, nil
+}
+
+func NewRepository() UserRepository {
+	return &userRepository{}
+}
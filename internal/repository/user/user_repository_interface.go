@@ -0,0 +1,22 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type UserRepository interface {
+	FindByID(ctx context.Context, tx *sql.Tx, id uint64) (*entity.User, error)
+	FindByUsername(ctx context.Context, tx *sql.Tx, username string) (*entity.User, error)
+	UpdateProfile(ctx context.Context, tx *sql.Tx, u entity.User) (*entity.User, error)
+	UpdateRole(ctx context.Context, tx *sql.Tx, id uint64, role string) error
+	Follow(ctx context.Context, tx *sql.Tx, f entity.UserFollow) error
+	Unfollow(ctx context.Context, tx *sql.Tx, followerID uint64, followeeID uint64) error
+	FindFollowers(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.UserFollow, error)
+	FindFollowing(ctx context.Context, tx *sql.Tx, userID uint64, page pagination.Page) (*[]entity.UserFollow, error)
+	CountFollowers(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error)
+	CountFollowing(ctx context.Context, tx *sql.Tx, userID uint64) (*uint64, error)
+}
@@ -6,17 +6,24 @@ import (
 
 	"github.com/gorilla/mux"
 	authenticationhandler "github.com/mrizkimaulidan/storial/internal/handler/authentication"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
 	authenticationrepo "github.com/mrizkimaulidan/storial/internal/repository/authentication"
+	tokenrepo "github.com/mrizkimaulidan/storial/internal/repository/token"
 	authenticationservice "github.com/mrizkimaulidan/storial/internal/service/authentication"
 )
 
 // Register routes.
 func RegisterRoutes(r *mux.Router, db *sql.DB) {
 	authenticationRepository := authenticationrepo.NewRepository()
-	authenticationService := authenticationservice.NewService(authenticationRepository, db)
+	tokenRepository := tokenrepo.NewRepository()
+	authenticationService := authenticationservice.NewService(authenticationRepository, tokenRepository, db)
 	authenticationhandler := authenticationhandler.NewHandler(authenticationService)
 
+	middleware := middleware.New(db)
+
 	v1 := r.PathPrefix("/api/v1").Subrouter()
 	v1.Handle("/register", authenticationhandler.Register()).Methods(http.MethodPost)
 	v1.Handle("/login", authenticationhandler.Login()).Methods(http.MethodPost)
+	v1.Handle("/token/refresh", authenticationhandler.Refresh()).Methods(http.MethodPost)
+	v1.Handle("/logout", middleware.JWTAuthorization(authenticationhandler.Logout())).Methods(http.MethodPost)
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	categoryhandler "github.com/mrizkimaulidan/storial/internal/handler/category"
 	"github.com/mrizkimaulidan/storial/internal/middleware"
 	categoryrepo "github.com/mrizkimaulidan/storial/internal/repository/category"
@@ -19,9 +20,15 @@ func RegisterRoutes(r *mux.Router, db *sql.DB) {
 	categoryService := categoryservice.NewService(categoryRepository, storyRepository, db)
 	categoryHandler := categoryhandler.NewHandler(categoryService)
 
-	middleware := middleware.New()
+	middleware := middleware.New(db)
 
 	v1 := r.PathPrefix("/api/v1").Subrouter()
 	v1.Handle("/books/categories", categoryHandler.GetAllCategory()).Methods(http.MethodGet)
+
+	admin := middleware.RequireRole(entity.ROLE_ADMIN)
+	v1.Handle("/categories", admin(categoryHandler.Store())).Methods(http.MethodPost)
+	v1.Handle("/categories/order", admin(categoryHandler.Reorder())).Methods(http.MethodPut)
+	v1.Handle("/categories/{categoryId}", admin(categoryHandler.Update())).Methods(http.MethodPut, http.MethodPatch)
+	v1.Handle("/categories/{categoryId}", admin(categoryHandler.Delete())).Methods(http.MethodDelete)
 	v1.Use(middleware.JWTAuthorization)
 }
@@ -5,28 +5,40 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	chapterhandler "github.com/mrizkimaulidan/storial/internal/handler/chapter"
 	"github.com/mrizkimaulidan/storial/internal/middleware"
 	chapterrepository "github.com/mrizkimaulidan/storial/internal/repository/chapter"
 	"github.com/mrizkimaulidan/storial/internal/repository/story"
 	chapterservice "github.com/mrizkimaulidan/storial/internal/service/chapter"
+	"github.com/mrizkimaulidan/storial/internal/tracker"
 )
 
 // Register routes.
-func RegisterRoutes(r *mux.Router, db *sql.DB) {
+func RegisterRoutes(r *mux.Router, db *sql.DB, viewTracker *tracker.ViewTracker) {
 	chapterRepository := chapterrepository.NewRepository()
 	storyRepository := story.NewRepository()
 	chapterService := chapterservice.NewService(chapterRepository, storyRepository, db)
-	chapterHandler := chapterhandler.NewHandler(chapterService)
+	chapterHandler := chapterhandler.NewHandler(chapterService, viewTracker)
 
-	middleware := middleware.New()
+	middleware := middleware.New(db)
+
+	// reading the chapter is open to anonymous readers
+	public := r.PathPrefix("/api/v1").Subrouter()
+	public.Handle("/book/{storySlug}/{chapterSlug}", chapterHandler.GetChapter()).Methods(http.MethodGet)
+	public.Use(middleware.OptionalJWTAuthorization)
 
 	v1 := r.PathPrefix("/api/v1").Subrouter()
-	v1.Handle("/add-chapter/{storySlug}", chapterHandler.AddChapter()).Methods(http.MethodPost)
-	v1.Handle("/edit-chapter/{storySlug}/{chapterSlug}", chapterHandler.EditChapter()).Methods(http.MethodPut, http.MethodPatch)
-	v1.Handle("/book/{storySlug}/{chapterSlug}", chapterHandler.GetChapter()).Methods(http.MethodGet)
+	v1.Handle("/add-chapter/{storySlug}", middleware.RequireRole(entity.ROLE_WRITER)(chapterHandler.AddChapter())).Methods(http.MethodPost)
+	v1.Handle("/edit-chapter/{storySlug}/{chapterSlug}", middleware.RequireRole(entity.ROLE_WRITER)(chapterHandler.EditChapter())).Methods(http.MethodPut, http.MethodPatch)
 	v1.Handle("/writers/chapter/{chapterId}/delete", chapterHandler.DeleteChapter()).Methods(http.MethodDelete)
 	v1.Handle("/books/{storyId}/chapters", chapterHandler.GetChapters()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/chapters/order", middleware.RequireRole(entity.ROLE_WRITER)(chapterHandler.ReorderChapters())).Methods(http.MethodPut)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/revisions", chapterHandler.GetRevisions()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/revisions/diff", chapterHandler.DiffRevisions()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/revisions/{revisionId}", chapterHandler.GetRevision()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/revisions/{revisionId}/restore", middleware.RequireRole(entity.ROLE_WRITER)(chapterHandler.RestoreRevision())).Methods(http.MethodPost)
 	v1.Handle("/books/{storyId}/chapters/{chapterId}/votes/up", chapterHandler.LikeChapter()).Methods(http.MethodPost)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/votes/up", chapterHandler.UnlikeChapter()).Methods(http.MethodDelete)
 	v1.Use(middleware.JWTAuthorization)
 }
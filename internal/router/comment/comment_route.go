@@ -0,0 +1,31 @@
+package comment
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	commenthandler "github.com/mrizkimaulidan/storial/internal/handler/comment"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
+	chapterrepo "github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	commentrepo "github.com/mrizkimaulidan/storial/internal/repository/comment"
+	commentservice "github.com/mrizkimaulidan/storial/internal/service/comment"
+)
+
+// Register routes.
+func RegisterRoutes(r *mux.Router, db *sql.DB) {
+	commentRepository := commentrepo.NewRepository()
+	chapterRepository := chapterrepo.NewRepository()
+	commentService := commentservice.NewService(commentRepository, chapterRepository, db)
+	commentHandler := commenthandler.NewHandler(commentService)
+
+	middleware := middleware.New(db)
+
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/comments", commentHandler.GetComments()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/comments", commentHandler.AddComment()).Methods(http.MethodPost)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/comments/{commentId}/replies", commentHandler.ReplyComment()).Methods(http.MethodPost)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/comments/{commentId}", commentHandler.EditComment()).Methods(http.MethodPut, http.MethodPatch)
+	v1.Handle("/books/{storyId}/chapters/{chapterId}/comments/{commentId}", commentHandler.DeleteComment()).Methods(http.MethodDelete)
+	v1.Use(middleware.JWTAuthorization)
+}
@@ -0,0 +1,33 @@
+package library
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	libraryhandler "github.com/mrizkimaulidan/storial/internal/handler/library"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
+	chapterrepo "github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	libraryrepo "github.com/mrizkimaulidan/storial/internal/repository/library"
+	storyrepo "github.com/mrizkimaulidan/storial/internal/repository/story"
+	libraryservice "github.com/mrizkimaulidan/storial/internal/service/library"
+)
+
+// Register routes.
+func RegisterRoutes(r *mux.Router, db *sql.DB) {
+	libraryRepository := libraryrepo.NewRepository()
+	storyRepository := storyrepo.NewRepository()
+	chapterRepository := chapterrepo.NewRepository()
+	libraryService := libraryservice.NewService(libraryRepository, storyRepository, chapterRepository, db)
+	libraryHandler := libraryhandler.NewHandler(libraryService)
+
+	middleware := middleware.New(db)
+
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Handle("/me/library", libraryHandler.GetLibrary()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/bookmark", libraryHandler.Bookmark()).Methods(http.MethodPost)
+	v1.Handle("/books/{storyId}/bookmark", libraryHandler.Unbookmark()).Methods(http.MethodDelete)
+	v1.Handle("/books/{storyId}/progress", libraryHandler.GetProgress()).Methods(http.MethodGet)
+	v1.Handle("/books/{storyId}/progress", libraryHandler.UpdateProgress()).Methods(http.MethodPut)
+	v1.Use(middleware.JWTAuthorization)
+}
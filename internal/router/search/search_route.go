@@ -0,0 +1,25 @@
+package search
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	searchhandler "github.com/mrizkimaulidan/storial/internal/handler/search"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
+	searchrepo "github.com/mrizkimaulidan/storial/internal/repository/search"
+	searchservice "github.com/mrizkimaulidan/storial/internal/service/search"
+)
+
+// Register routes.
+func RegisterRoutes(r *mux.Router, db *sql.DB) {
+	searchRepository := searchrepo.NewRepository()
+	searchService := searchservice.NewService(searchRepository, db)
+	searchHandler := searchhandler.NewHandler(searchService)
+
+	middleware := middleware.New(db)
+
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Handle("/search", searchHandler.Search()).Methods(http.MethodGet)
+	v1.Use(middleware.JWTAuthorization)
+}
@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	statshandler "github.com/mrizkimaulidan/storial/internal/handler/stats"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
+	statsrepo "github.com/mrizkimaulidan/storial/internal/repository/stats"
+	statsservice "github.com/mrizkimaulidan/storial/internal/service/stats"
+)
+
+// Register routes.
+func RegisterRoutes(r *mux.Router, db *sql.DB) {
+	statsRepository := statsrepo.NewRepository()
+	statsService := statsservice.NewService(statsRepository, db)
+	statsHandler := statshandler.NewHandler(statsService)
+
+	middleware := middleware.New(db)
+
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Handle("/writers/stats", middleware.RequireRole(entity.ROLE_WRITER)(statsHandler.GetWriterStats())).Methods(http.MethodGet)
+	v1.Use(middleware.JWTAuthorization)
+}
@@ -5,11 +5,13 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	storyhandler "github.com/mrizkimaulidan/storial/internal/handler/story"
 	"github.com/mrizkimaulidan/storial/internal/middleware"
 	chapterrepo "github.com/mrizkimaulidan/storial/internal/repository/chapter"
 	storyrepo "github.com/mrizkimaulidan/storial/internal/repository/story"
 	chapterservice "github.com/mrizkimaulidan/storial/internal/service/chapter"
+	"github.com/mrizkimaulidan/storial/internal/service/file"
 	storyservice "github.com/mrizkimaulidan/storial/internal/service/story"
 )
 
@@ -18,20 +20,23 @@ func RegisterRoutes(r *mux.Router, db *sql.DB) {
 	storyRepository := storyrepo.NewRepository()
 	chapterRepository := chapterrepo.NewRepository()
 	chapterService := chapterservice.NewService(chapterRepository, storyRepository, db)
-	storyService := storyservice.NewService(storyRepository, chapterRepository, chapterService, db)
+	fileService := file.NewService()
+	storyService := storyservice.NewService(storyRepository, chapterRepository, chapterService, fileService, db)
 	storyHandler := storyhandler.NewHandler(storyService)
 
 	v1 := r.PathPrefix("/api/v1").Subrouter()
 
-	middleware := middleware.New()
+	middleware := middleware.New(db)
 
-	v1.Handle("/add-book", storyHandler.Store()).Methods(http.MethodPost)
-	v1.Handle("/edit-book/{slug}", storyHandler.Update()).Methods(http.MethodPut, http.MethodPatch)
+	v1.Handle("/add-book", middleware.RequireRole(entity.ROLE_WRITER)(storyHandler.Store())).Methods(http.MethodPost)
+	v1.Handle("/edit-book/{slug}", middleware.RequireRole(entity.ROLE_WRITER)(storyHandler.Update())).Methods(http.MethodPut, http.MethodPatch)
 	v1.Handle("/book_front/{filename}", storyHandler.LoadImageCover()).Methods(http.MethodGet)
 	v1.Handle("/writers/book/{id}/delete", storyHandler.Delete()).Methods(http.MethodDelete)
 	v1.Handle("/user/books", storyHandler.GetAll()).Methods(http.MethodGet)
 	v1.Handle("/book/{slug}", storyHandler.GetBySlug()).Methods(http.MethodGet)
+	v1.Handle("/book/{slug}/export.epub", storyHandler.ExportEpub()).Methods(http.MethodGet)
 	v1.Handle("/book-list", storyHandler.Filter()).Methods(http.MethodGet)
+	v1.Handle("/feed", storyHandler.Feed()).Methods(http.MethodGet)
 	v1.Handle("/{categorySlug}", storyHandler.FilterByCategorySlug()).Methods(http.MethodGet)
 	v1.Use(middleware.JWTAuthorization)
 }
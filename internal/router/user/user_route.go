@@ -0,0 +1,36 @@
+package user
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	userhandler "github.com/mrizkimaulidan/storial/internal/handler/user"
+	"github.com/mrizkimaulidan/storial/internal/middleware"
+	chapterrepo "github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	storyrepo "github.com/mrizkimaulidan/storial/internal/repository/story"
+	userrepo "github.com/mrizkimaulidan/storial/internal/repository/user"
+	userservice "github.com/mrizkimaulidan/storial/internal/service/user"
+)
+
+// Register routes.
+func RegisterRoutes(r *mux.Router, db *sql.DB) {
+	userRepository := userrepo.NewRepository()
+	storyRepository := storyrepo.NewRepository()
+	chapterRepository := chapterrepo.NewRepository()
+	userService := userservice.NewService(userRepository, storyRepository, chapterRepository, db)
+	userHandler := userhandler.NewHandler(userService)
+
+	middleware := middleware.New(db)
+
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Handle("/users/{username}", userHandler.GetByUsername()).Methods(http.MethodGet)
+	v1.Handle("/users/{username}/follow", userHandler.Follow()).Methods(http.MethodPost)
+	v1.Handle("/users/{username}/follow", userHandler.Unfollow()).Methods(http.MethodDelete)
+	v1.Handle("/users/{username}/followers", userHandler.GetFollowers()).Methods(http.MethodGet)
+	v1.Handle("/users/{username}/following", userHandler.GetFollowing()).Methods(http.MethodGet)
+	v1.Handle("/users/{username}/role", middleware.RequireRole(entity.ROLE_ADMIN)(userHandler.UpdateRole())).Methods(http.MethodPut, http.MethodPatch)
+	v1.Handle("/me", userHandler.UpdateProfile()).Methods(http.MethodPatch)
+	v1.Use(middleware.JWTAuthorization)
+}
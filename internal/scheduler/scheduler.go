@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	gotime "time"
+
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+// Scheduler publish the scheduled stories and chapters once their
+// publish time has passed, checked on every interval.
+type Scheduler struct {
+	storyRepository   story.StoryRepository
+	chapterRepository chapter.ChapterRepository
+	db                *sql.DB
+	interval          gotime.Duration
+	done              chan struct{}
+}
+
+// Run the scheduler on background goroutine until the context canceled.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		defer close(s.done)
+
+		ticker := gotime.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		log.Println("scheduler running every", s.interval)
+		for {
+			// not using the canceled context, the running publish
+			// is finished first before the scheduler stopped
+			err := s.publishDue(context.Background())
+			if err != nil {
+				log.Println("[ERROR] scheduler", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait until the running scheduler stopped.
+func (s *Scheduler) Wait() {
+	<-s.done
+}
+
+// Publish every due story and chapter inside single transaction.
+// Rolled back when failing, the due items will be retried on the next tick
+// instead of panicking the background goroutine.
+func (s *Scheduler) publishDue(ctx context.Context) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	now := time.CurrentTimeToUnixTimestamp()
+
+	stories, err := s.storyRepository.PublishDue(ctx, tx, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	chapters, err := s.chapterRepository.PublishDue(ctx, tx, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	if *stories > 0 || *chapters > 0 {
+		log.Printf("scheduler published %d stories and %d chapters", *stories, *chapters)
+	}
+
+	return nil
+}
+
+func New(sr story.StoryRepository, cr chapter.ChapterRepository, db *sql.DB, interval gotime.Duration) *Scheduler {
+	return &Scheduler{
+		storyRepository:   sr,
+		chapterRepository: cr,
+		db:                db,
+		interval:          interval,
+		done:              make(chan struct{}),
+	}
+}
@@ -2,26 +2,43 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mrizkimaulidan/storial/internal/config"
 	"github.com/mrizkimaulidan/storial/internal/database"
 	"github.com/mrizkimaulidan/storial/internal/middleware"
+	chapterrepository "github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	libraryrepository "github.com/mrizkimaulidan/storial/internal/repository/library"
+	storyrepository "github.com/mrizkimaulidan/storial/internal/repository/story"
 	"github.com/mrizkimaulidan/storial/internal/router/authentication"
 	"github.com/mrizkimaulidan/storial/internal/router/category"
 	"github.com/mrizkimaulidan/storial/internal/router/chapter"
+	"github.com/mrizkimaulidan/storial/internal/router/comment"
+	"github.com/mrizkimaulidan/storial/internal/router/library"
+	"github.com/mrizkimaulidan/storial/internal/router/search"
+	"github.com/mrizkimaulidan/storial/internal/router/stats"
 	"github.com/mrizkimaulidan/storial/internal/router/story"
+	"github.com/mrizkimaulidan/storial/internal/router/user"
+	"github.com/mrizkimaulidan/storial/internal/scheduler"
+	"github.com/mrizkimaulidan/storial/internal/service/file"
+	"github.com/mrizkimaulidan/storial/internal/tracker"
+	"github.com/mrizkimaulidan/storial/pkg/snowflake"
 )
 
 type Server struct {
 	router *mux.Router
 	c      *config.Config
+	db     *sql.DB
+	views  *tracker.ViewTracker
 }
 
 func NewServer() *Server {
@@ -35,9 +52,10 @@ func NewServer() *Server {
 // The server will be closed until cancel signal received.
 // And handling the shutdown gracefully.
 func (s *Server) Run() {
+	s.node()
 	s.routes()
 
-	middleware := middleware.New()
+	middleware := middleware.New(s.db)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", s.c.APP_PORT),
@@ -46,6 +64,17 @@ func (s *Server) Run() {
 		Handler:      middleware.LoggingMiddleware(s.router),
 	}
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	scheduler := s.scheduler()
+	scheduler.Start(schedulerCtx)
+
+	viewsCtx, stopViews := context.WithCancel(context.Background())
+	defer stopViews()
+
+	s.views.Start(viewsCtx)
+
 	go func() {
 		log.Println("server running at", srv.Addr)
 		err := srv.ListenAndServe()
@@ -69,15 +98,97 @@ func (s *Server) Run() {
 		log.Fatalln("error shutting down server", err)
 	}
 
+	log.Println("stopping scheduler..")
+	stopScheduler()
+	scheduler.Wait()
+
+	log.Println("flushing chapter views..")
+	stopViews()
+	s.views.Wait()
+
+	err = s.db.Close()
+	if err != nil {
+		log.Fatalln("error closing database", err)
+	}
+
 	log.Println("server shutted down successfully")
 }
 
 // Setup routes endpoint.
 func (s *Server) routes() {
-	db := database.NewDatabase().Open()
+	s.db = database.NewDatabase().Open()
+	s.views = s.viewTracker()
+
+	authentication.RegisterRoutes(s.router, s.db)
+	// must be registered before story routes, the /{categorySlug}
+	// story route will match any single segment path
+	search.RegisterRoutes(s.router, s.db)
+	user.RegisterRoutes(s.router, s.db)
+	category.RegisterRoutes(s.router, s.db)
+	story.RegisterRoutes(s.router, s.db)
+	chapter.RegisterRoutes(s.router, s.db, s.views)
+	comment.RegisterRoutes(s.router, s.db)
+	library.RegisterRoutes(s.router, s.db)
+	stats.RegisterRoutes(s.router, s.db)
+
+	// files saved on local disk are served by the server itself
+	if s.c.STORAGE_DRIVER == file.DRIVER_LOCAL {
+		prefix := strings.TrimSuffix(s.c.STORAGE_LOCAL_URL, "/") + "/"
+		s.router.PathPrefix(prefix).Handler(http.StripPrefix(prefix, noDirectoryListing(http.FileServer(http.Dir(s.c.STORAGE_LOCAL_PATH)))))
+	}
+}
+
+// Setup node ID of the ID generator.
+func (s *Server) node() {
+	node, err := strconv.ParseInt(s.c.NODE_ID, 10, 64)
+	if err != nil {
+		log.Fatalln("invalid NODE_ID", s.c.NODE_ID)
+	}
+
+	err = snowflake.SetNode(node)
+	if err != nil {
+		log.Fatalln("invalid NODE_ID", err)
+	}
+}
+
+// Setup the scheduler publishing the scheduled stories and chapters.
+func (s *Server) scheduler() *scheduler.Scheduler {
+	interval, err := strconv.Atoi(s.c.SCHEDULER_INTERVAL)
+	if err != nil || interval <= 0 {
+		log.Fatalln("invalid SCHEDULER_INTERVAL", s.c.SCHEDULER_INTERVAL)
+	}
+
+	return scheduler.New(storyrepository.NewRepository(), chapterrepository.NewRepository(), s.db, time.Duration(interval)*time.Second)
+}
+
+// Setup the tracker counting the chapter views and saving the reader progress.
+func (s *Server) viewTracker() *tracker.ViewTracker {
+	window, err := strconv.Atoi(s.c.VIEW_DEDUP_WINDOW)
+	if err != nil || window <= 0 {
+		log.Fatalln("invalid VIEW_DEDUP_WINDOW", s.c.VIEW_DEDUP_WINDOW)
+	}
+
+	interval, err := strconv.Atoi(s.c.VIEW_FLUSH_INTERVAL)
+	if err != nil || interval <= 0 {
+		log.Fatalln("invalid VIEW_FLUSH_INTERVAL", s.c.VIEW_FLUSH_INTERVAL)
+	}
+
+	batchSize, err := strconv.Atoi(s.c.VIEW_BATCH_SIZE)
+	if err != nil || batchSize <= 0 {
+		log.Fatalln("invalid VIEW_BATCH_SIZE", s.c.VIEW_BATCH_SIZE)
+	}
+
+	return tracker.New(chapterrepository.NewRepository(), libraryrepository.NewRepository(), s.db, time.Duration(window)*time.Second, time.Duration(interval)*time.Second, batchSize)
+}
+
+// Prevent directory listing of the file server.
+func noDirectoryListing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
 
-	authentication.RegisterRoutes(s.router, db)
-	story.RegisterRoutes(s.router, db)
-	chapter.RegisterRoutes(s.router, db)
-	category.RegisterRoutes(s.router, db)
+		next.ServeHTTP(w, r)
+	})
 }
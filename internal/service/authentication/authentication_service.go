@@ -9,6 +9,7 @@ import (
 	"github.com/mrizkimaulidan/storial/internal/entity"
 	model "github.com/mrizkimaulidan/storial/internal/model/authentication"
 	"github.com/mrizkimaulidan/storial/internal/repository/authentication"
+	"github.com/mrizkimaulidan/storial/internal/repository/token"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/authentication"
 	"github.com/mrizkimaulidan/storial/pkg/jwt"
 	"github.com/mrizkimaulidan/storial/pkg/password"
@@ -17,6 +18,7 @@ import (
 
 type authenticationService struct {
 	authenticationRepository authentication.AuthenticationRepository
+	tokenRepository          token.TokenRepository
 	db                       *sql.DB
 }
 
@@ -66,28 +68,36 @@ func (as *authenticationService) Register(ctx context.Context, r model.RegisterR
 		Email:     r.Email,
 		Password:  password,
 		Sex:       uint8(sex),
+		Role:      entity.ROLE_WRITER,
 		CreatedAt: time.CurrentTimeToUnixTimestamp(),
 	}
 
-	s.Id = uint64(s.GenerateID())
+	s.Id = s.GenerateID()
 
 	registeredUser, err := as.authenticationRepository.Register(ctx, tx, s)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := jwt.GenerateToken(*registeredUser)
+	accessToken, err := jwt.GenerateToken(*registeredUser)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := as.issueRefreshToken(ctx, tx, registeredUser.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.RegisterResponse{
-		Id:       registeredUser.Id,
-		Name:     registeredUser.Name,
-		Username: registeredUser.Username,
-		Email:    registeredUser.Email,
-		Token:    token,
-		Sex:      registeredUser.GetGenderName(),
+		Id:           registeredUser.Id,
+		Name:         registeredUser.Name,
+		Username:     registeredUser.Username,
+		Email:        registeredUser.Email,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		Sex:          registeredUser.GetGenderName(),
+		Role:         registeredUser.Role,
 	}, nil
 }
 
@@ -96,6 +106,7 @@ func (as *authenticationService) Login(ctx context.Context, r model.LoginRequest
 	if err != nil {
 		return nil, err
 	}
+	defer database.CommitOrRollback(tx)
 
 	err = r.Validate()
 	if err != nil {
@@ -117,24 +128,173 @@ func (as *authenticationService) Login(ctx context.Context, r model.LoginRequest
 		return nil, exception.ErrPasswordAreWrong
 	}
 
-	token, err := jwt.GenerateToken(*user)
+	accessToken, err := jwt.GenerateToken(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := as.issueRefreshToken(ctx, tx, user.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.LoginResponse{
-		Id:       user.Id,
-		Name:     user.Name,
-		Email:    user.Email,
-		Username: user.Username,
-		Token:    token,
-		Sex:      user.GetGenderName(),
+		Id:           user.Id,
+		Name:         user.Name,
+		Email:        user.Email,
+		Username:     user.Username,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		Sex:          user.GetGenderName(),
+		Role:         user.Role,
 	}, nil
 }
 
-func NewService(authenticationRepository authentication.AuthenticationRepository, db *sql.DB) AuthenticationService {
+// Exchange refresh token with new access token and new refresh token.
+// The old refresh token is revoked, when a revoked refresh token is used again
+// all refresh token owned by the user are revoked because the token may be stolen.
+func (as *authenticationService) Refresh(ctx context.Context, r model.RefreshTokenRequest) (*model.RefreshTokenResponse, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := as.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	rt, err := as.tokenRepository.FindRefreshTokenByHash(ctx, tx, jwt.HashRefreshToken(r.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.CurrentTimeToUnixTimestamp()
+
+	if rt.IsRevoked() {
+		err := as.tokenRepository.RevokeAllRefreshTokenByUserID(ctx, tx, rt.UserID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, exception.ErrRefreshTokenRevoked
+	}
+
+	if rt.IsExpired(now) {
+		return nil, exception.ErrRefreshTokenExpired
+	}
+
+	user, err := as.authenticationRepository.FindByID(ctx, tx, rt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, newRt, err := as.saveRefreshToken(ctx, tx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = as.tokenRepository.RevokeRefreshToken(ctx, tx, rt.Id, &newRt.Id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := jwt.GenerateToken(*user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RefreshTokenResponse{
+		Token:        accessToken,
+		RefreshToken: plain,
+	}, nil
+}
+
+// Revoke the current access token and the refresh token if provided.
+func (as *authenticationService) Logout(ctx context.Context, r model.LogoutRequest) (*model.LogoutResponse, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := as.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	now := time.CurrentTimeToUnixTimestamp()
+
+	err = as.tokenRepository.DeleteExpiredRevokedAccessToken(ctx, tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.TokenID != "" {
+		err := as.tokenRepository.SaveRevokedAccessToken(ctx, tx, r.TokenID, r.TokenExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.RefreshToken != "" {
+		rt, err := as.tokenRepository.FindRefreshTokenByHash(ctx, tx, jwt.HashRefreshToken(r.RefreshToken))
+		if err != nil {
+			return nil, err
+		}
+
+		if rt.UserID != r.UserID {
+			return nil, exception.ErrRefreshTokenInvalid
+		}
+
+		err = as.tokenRepository.RevokeRefreshToken(ctx, tx, rt.Id, nil, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.LogoutResponse{
+		Status: true,
+	}, nil
+}
+
+// Issue new refresh token for the userID.
+// Returning the plain refresh token, only the hashes is saved.
+func (as *authenticationService) issueRefreshToken(ctx context.Context, tx *sql.Tx, userID uint64) (string, error) {
+	plain, _, err := as.saveRefreshToken(ctx, tx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+// Generate and save new refresh token for the userID.
+func (as *authenticationService) saveRefreshToken(ctx context.Context, tx *sql.Tx, userID uint64) (string, *entity.RefreshToken, error) {
+	plain, hash, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.CurrentTimeToUnixTimestamp()
+	rt, err := as.tokenRepository.SaveRefreshToken(ctx, tx, entity.RefreshToken{
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: now + uint64(jwt.REFRESH_TOKEN_TTL.Milliseconds()),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plain, rt, nil
+}
+
+func NewService(authenticationRepository authentication.AuthenticationRepository, tokenRepository token.TokenRepository, db *sql.DB) AuthenticationService {
 	return &authenticationService{
 		authenticationRepository: authenticationRepository,
+		tokenRepository:          tokenRepository,
 		db:                       db,
 	}
 }
@@ -9,4 +9,6 @@ import (
 type AuthenticationService interface {
 	Register(ctx context.Context, r authentication.RegisterRequest) (*authentication.RegisterResponse, error)
 	Login(ctx context.Context, r authentication.LoginRequest) (*authentication.LoginResponse, error)
+	Refresh(ctx context.Context, r authentication.RefreshTokenRequest) (*authentication.RefreshTokenResponse, error)
+	Logout(ctx context.Context, r authentication.LogoutRequest) (*authentication.LogoutResponse, error)
 }
@@ -3,13 +3,30 @@ package category
 import (
 	"context"
 	"database/sql"
+	"strconv"
 
 	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/entity"
 	model "github.com/mrizkimaulidan/storial/internal/model/category"
 	"github.com/mrizkimaulidan/storial/internal/repository/category"
 	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/category"
 )
 
+// Slugs used by the single segment routes, the category with these
+// slugs can not be reached from /api/v1/{categorySlug}.
+var RESERVED_SLUGS = map[string]bool{
+	"add-book":   true,
+	"book-list":  true,
+	"categories": true,
+	"feed":       true,
+	"login":      true,
+	"logout":     true,
+	"me":         true,
+	"register":   true,
+	"search":     true,
+}
+
 type categoryService struct {
 	categoryRepository category.CategoryRepository
 	storyRepository    story.StoryRepository
@@ -39,6 +56,7 @@ func (cs *categoryService) GetAll(ctx context.Context) (*[]model.CategoryRespons
 			Id:          c.Id,
 			Name:        c.Name,
 			Slug:        c.Slug,
+			Position:    c.Position,
 			StoryCounts: *storyCounts,
 		}
 
@@ -48,6 +66,225 @@ func (cs *categoryService) GetAll(ctx context.Context) (*[]model.CategoryRespons
 	return &categoriesResponse, nil
 }
 
+// Create new category placed on the last position.
+func (cs *categoryService) Create(ctx context.Context, r model.CreateCategoryRequest) (*model.CategoryResponseByAdmin, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var c entity.Category
+	slug, err := cs.generateSlug(ctx, tx, r.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := cs.categoryRepository.MaxPosition(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Name = r.Name
+	c.Slug = slug
+	c.Position = *position + 1
+
+	createdCategory, err := cs.categoryRepository.Save(ctx, tx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryResponse(*createdCategory), nil
+}
+
+// Rename the category, the slug is generated again from the new name.
+func (cs *categoryService) Rename(ctx context.Context, r model.UpdateCategoryRequest) (*model.CategoryResponseByAdmin, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cs.findCategory(ctx, tx, r.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	slug, err := cs.generateSlug(ctx, tx, r.Name, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Name = r.Name
+	c.Slug = slug
+
+	updatedCategory, err := cs.categoryRepository.Update(ctx, tx, *c)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryResponse(*updatedCategory), nil
+}
+
+// Delete the category. If the category still has stories, the stories
+// must be reassigned to another category on the same transaction,
+// otherwise throwing an err category has stories.
+func (cs *categoryService) Delete(ctx context.Context, r model.DeleteCategoryRequest) (*model.DeletedCategoryResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	c, err := cs.findCategory(ctx, tx, r.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	storyCounts, err := cs.storyRepository.CountStoryByCategoryID(ctx, tx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var reassigned uint64
+	if *storyCounts > 0 {
+		if r.ReassignTo == "" {
+			return nil, exception.ErrCategoryHasStories
+		}
+
+		target, err := cs.findCategory(ctx, tx, r.ReassignTo)
+		if err != nil {
+			return nil, err
+		}
+
+		if target.Id == c.Id {
+			return nil, exception.ErrInvalidReassignCategory
+		}
+
+		moved, err := cs.storyRepository.UpdateCategoryIDByCategoryID(ctx, tx, c.Id, target.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		reassigned = *moved
+	}
+
+	err = cs.categoryRepository.Delete(ctx, tx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletedCategoryResponse{
+		Status:            true,
+		ReassignedStories: reassigned,
+	}, nil
+}
+
+// Reorder the categories. The request must contain every category
+// exactly once, otherwise throwing an err invalid category order.
+func (cs *categoryService) Reorder(ctx context.Context, r model.ReorderCategoryRequest) (*[]model.CategoryResponseByAdmin, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := cs.categoryRepository.FindAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.CategoryIDs) != len(*categories) {
+		return nil, exception.ErrInvalidCategoryOrder
+	}
+
+	byID := make(map[uint64]entity.Category)
+	for _, c := range *categories {
+		byID[c.Id] = c
+	}
+
+	var categoriesResponse []model.CategoryResponseByAdmin
+	for i, categoryID := range r.CategoryIDs {
+		id, err := strconv.ParseUint(categoryID, 10, 64)
+		if err != nil {
+			return nil, exception.ErrInvalidCategoryOrder
+		}
+
+		c, ok := byID[id]
+		if !ok {
+			return nil, exception.ErrInvalidCategoryOrder
+		}
+
+		// prevent the same category ordered twice
+		delete(byID, id)
+
+		c.Position = uint64(i + 1)
+		err = cs.categoryRepository.UpdatePosition(ctx, tx, c.Id, c.Position)
+		if err != nil {
+			return nil, err
+		}
+
+		categoriesResponse = append(categoriesResponse, *categoryResponse(c))
+	}
+
+	return &categoriesResponse, nil
+}
+
+// Find the category by ID from the path.
+func (cs *categoryService) findCategory(ctx context.Context, tx *sql.Tx, categoryID string) (*entity.Category, error) {
+	id, err := strconv.ParseUint(categoryID, 10, 64)
+	if err != nil {
+		return nil, exception.ErrCategoryNotFound
+	}
+
+	return cs.categoryRepository.FindByID(ctx, tx, id)
+}
+
+// Generate slug from the category name. The slug must not be empty,
+// reserved or used by other category than the exceptID.
+func (cs *categoryService) generateSlug(ctx context.Context, tx *sql.Tx, name string, exceptID uint64) (string, error) {
+	var c entity.Category
+	slug := c.ToSlug(name)
+	if slug == "" || RESERVED_SLUGS[slug] {
+		return "", exception.ErrCategorySlugNotAvailable
+	}
+
+	exists, err := cs.categoryRepository.CheckIfSlugExists(ctx, tx, slug, exceptID)
+	if err != nil {
+		return "", err
+	}
+
+	if *exists {
+		return "", exception.ErrCategorySlugNotAvailable
+	}
+
+	return slug, nil
+}
+
+func categoryResponse(c entity.Category) *model.CategoryResponseByAdmin {
+	return &model.CategoryResponseByAdmin{
+		Id:       c.Id,
+		Slug:     c.Slug,
+		Name:     c.Name,
+		Position: c.Position,
+	}
+}
+
 func NewService(cr category.CategoryRepository, sr story.StoryRepository, db *sql.DB) CategoryService {
 	return &categoryService{
 		categoryRepository: cr,
@@ -8,4 +8,8 @@ import (
 
 type CategoryService interface {
 	GetAll(ctx context.Context) (*[]model.CategoryResponse, error)
+	Create(ctx context.Context, r model.CreateCategoryRequest) (*model.CategoryResponseByAdmin, error)
+	Rename(ctx context.Context, r model.UpdateCategoryRequest) (*model.CategoryResponseByAdmin, error)
+	Delete(ctx context.Context, r model.DeleteCategoryRequest) (*model.DeletedCategoryResponse, error)
+	Reorder(ctx context.Context, r model.ReorderCategoryRequest) (*[]model.CategoryResponseByAdmin, error)
 }
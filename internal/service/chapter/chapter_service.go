@@ -3,7 +3,7 @@ package chapter
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"strconv"
 
 	"github.com/mrizkimaulidan/storial/internal/database"
@@ -13,28 +13,95 @@ import (
 	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
 	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
 	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	"github.com/mrizkimaulidan/storial/pkg/diff"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	slugpkg "github.com/mrizkimaulidan/storial/pkg/slug"
 	"github.com/mrizkimaulidan/storial/pkg/time"
 )
 
+// Revision ID that refer to the current chapter content.
+var REVISION_CURRENT = "current"
+
 type chapterService struct {
 	chapterRepository chapter.ChapterRepository
 	storyRepository   story.StoryRepository
 	db                *sql.DB
 }
 
-func (cs *chapterService) LikeChapter(ctx context.Context, storyID string, chapterID string, userID uint64) (*model.LikedChapterResponse, error) {
+func (cs *chapterService) LikeChapter(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string) (*model.LikedChapterResponse, error) {
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
-	sId, err := strconv.Atoi(storyID)
+	chapter, err := cs.findVisibleChapter(ctx, tx, viewer, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := viewer.UserID
+	if chapter.Story.UserID == userID {
+		return nil, exception.ErrCannotLikeYourOwnChapter
+	}
+
+	err = cs.chapterRepository.SaveChapterLikes(ctx, tx, chapter.Id, userID, time.CurrentTimeToUnixTimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.likedChapterResponse(ctx, tx, chapter.Id, userID)
+}
+
+func (cs *chapterService) UnlikeChapter(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string) (*model.LikedChapterResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	chapter, err := cs.findVisibleChapter(ctx, tx, viewer, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.chapterRepository.DeleteChapterLikes(ctx, tx, chapter.Id, viewer.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	return cs.likedChapterResponse(ctx, tx, chapter.Id, viewer.UserID)
+}
+
+// Find the chapter of the story visible to the viewer, the hidden chapter
+// is treated as not found so the draft existence is not leaked.
+// Chapter of adult story is restricted to the viewer allowed to read it.
+func (cs *chapterService) findVisibleChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, storyID string, chapterID string) (*entity.Chapter, error) {
+	chapter, err := cs.findChapterByStoryID(ctx, tx, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanSeeChapter(*chapter, chapter.Story) {
+		return nil, exception.ErrChapterNotFound
+	}
+
+	if !viewer.CanReadAdultStory(chapter.Story) {
+		return nil, storyexception.ErrAdultContentRestricted
+	}
+
+	return chapter, nil
+}
+
+// Find the chapter and make sure it belongs to the story.
+func (cs *chapterService) findChapterByStoryID(ctx context.Context, tx *sql.Tx, storyID string, chapterID string) (*entity.Chapter, error) {
+	sId, err := strconv.Atoi(storyID)
+	if err != nil {
+		return nil, exception.ErrChapterNotFound
+	}
+
 	_, err = cs.storyRepository.FindByID(ctx, tx, uint64(sId))
 	if err != nil {
 		return nil, err
@@ -42,7 +109,7 @@ func (cs *chapterService) LikeChapter(ctx context.Context, storyID string, chapt
 
 	cId, err := strconv.Atoi(chapterID)
 	if err != nil {
-		return nil, err
+		return nil, exception.ErrChapterNotFound
 	}
 
 	chapter, err := cs.chapterRepository.FindByID(ctx, tx, uint64(cId))
@@ -50,22 +117,34 @@ func (cs *chapterService) LikeChapter(ctx context.Context, storyID string, chapt
 		return nil, err
 	}
 
-	if chapter.Story.UserID == userID {
-		return nil, exception.ErrCannotLikeYourOwnChapter
+	if chapter.StoryID != uint64(sId) {
+		return nil, exception.ErrChapterNotFound
+	}
+
+	return chapter, nil
+}
+
+// Get the current likes of the chapter and the like state of the user.
+func (cs *chapterService) likedChapterResponse(ctx context.Context, tx *sql.Tx, chapterID uint64, userID uint64) (*model.LikedChapterResponse, error) {
+	likes, err := cs.chapterRepository.CountChapterLikesByChapterID(ctx, tx, chapterID)
+	if err != nil {
+		return nil, err
 	}
 
-	err = cs.chapterRepository.SaveChapterLikes(ctx, tx, uint64(cId), userID)
+	liked, err := cs.chapterRepository.CheckIfChapterLiked(ctx, tx, chapterID, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.LikedChapterResponse{
-		Status: true,
+		Status:    true,
+		Likes:     *likes,
+		LikedByMe: *liked,
 	}, nil
 }
 
 func (cs *chapterService) AddChapter(ctx context.Context, r model.CreateChapterRequest) (*model.CreatedChapterdResponse, error) {
-	tx, err := cs.db.Begin()
+	tx, err := cs.beginLocked(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -81,28 +160,58 @@ func (cs *chapterService) AddChapter(ctx context.Context, r model.CreateChapterR
 		return nil, err
 	}
 
+	publishAt, err := schedulePublish(r.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
 	story, err := cs.storyRepository.FindBySlugAndUserID(ctx, tx, r.StorySlug, r.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	// serialize adding the chapters of the story, so the concurrent requests
+	// do not read the same max position and slugs
+	err = cs.storyRepository.LockByID(ctx, tx, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	var c entity.Chapter
 	c = entity.Chapter{
-		Id:            uint64(c.GenerateID()),
+		Id:            c.GenerateID(),
 		StoryID:       story.Id,
 		Title:         r.Title,
-		Slug:          c.ToSlug(r.Title),
 		Body:          r.Body,
+		BodyFormat:    bodyFormat(r.Format, entity.BODY_FORMAT_PLAIN),
 		AuthorComment: r.AuthorComment,
-		IsPublished:   isPublished,
+		IsPublished:   isPublished && !publishAt.Valid,
 		CreatedAt:     time.CurrentTimeToUnixTimestamp(),
 		UpdatedAt:     time.CurrentTimeToUnixTimestamp(),
+		PublishAt:     publishAt,
 	}
 
-	c.WordCounts = uint64(c.CountChars())
-	c.ReadingTime = c.CalculateReadingTime()
+	c.RecordPublished(sql.NullInt64{}, c.CreatedAt)
+	c.CalculateMetrics()
+	c.RenderBody()
 
-	createdChapter, err := cs.chapterRepository.Save(ctx, tx, c)
+	// new chapter is placed after the last chapter of the story
+	position, err := cs.chapterRepository.MaxPositionByStoryID(ctx, tx, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Position = *position + 1
+
+	var createdChapter *entity.Chapter
+	err = cs.saveWithSlug(ctx, tx, r.Title, c, func(slug string) error {
+		c.Slug = slug
+
+		created, err := cs.chapterRepository.Save(ctx, tx, c)
+		createdChapter = created
+
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -113,10 +222,14 @@ func (cs *chapterService) AddChapter(ctx context.Context, r model.CreateChapterR
 		Title:         createdChapter.Title,
 		Slug:          createdChapter.Slug,
 		Body:          createdChapter.Body,
+		BodyFormat:    createdChapter.BodyFormat,
+		BodyHTML:      createdChapter.BodyHTML,
 		AuthorComment: createdChapter.AuthorComment,
 		WordCounts:    createdChapter.WordCounts,
 		ReadingTime:   createdChapter.ReadingTime,
 		IsPublished:   createdChapter.IsPublished,
+		PublishAt:     time.NullUnixToTime(createdChapter.PublishAt),
+		Position:      createdChapter.Position,
 		CreatedAt:     time.UnixToTime(createdChapter.CreatedAt),
 		UpdatedAt:     time.UnixToTime(createdChapter.UpdatedAt),
 	}, nil
@@ -139,31 +252,66 @@ func (cs *chapterService) EditChapter(ctx context.Context, r model.UpdateChapter
 		return nil, err
 	}
 
-	_, err = cs.chapterRepository.FindByStorySlugAndChapterSlug(ctx, tx, r.UserID, r.StorySlug, r.ChapterSlug)
+	previous, err := cs.chapterRepository.FindByStorySlugAndChapterSlug(ctx, tx, r.UserID, r.StorySlug, r.ChapterSlug)
 	if err != nil {
 		return nil, err
 	}
 
+	// the body keeps the previous format, validate the body length against it
+	if r.Format == "" {
+		r.Format = previous.BodyFormat
+
+		err = r.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	isPublished, err := strconv.ParseBool(r.IsPublished)
 	if err != nil {
 		return nil, err
 	}
 
-	var c entity.Chapter
-	c = entity.Chapter{
+	publishAt, err := schedulePublish(r.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c := entity.Chapter{
+		Id:            previous.Id,
 		StoryID:       story.Id,
 		Title:         r.Title,
-		Slug:          c.ToSlug(r.Title),
 		Body:          r.Body,
+		BodyFormat:    bodyFormat(r.Format, previous.BodyFormat),
 		AuthorComment: r.AuthorComment,
-		IsPublished:   isPublished,
+		IsPublished:   isPublished && !publishAt.Valid,
 		UpdatedAt:     time.CurrentTimeToUnixTimestamp(),
+		PublishAt:     publishAt,
 	}
 
-	c.WordCounts = uint64(c.CountChars())
-	c.ReadingTime = c.CalculateReadingTime()
+	c.RecordPublished(previous.PublishedAt, c.UpdatedAt)
+	c.CalculateMetrics()
+	c.RenderBody()
 
-	updatedChapter, err := cs.chapterRepository.Update(ctx, tx, r.UserID, r.StorySlug, r.ChapterSlug, c)
+	err = cs.saveRevision(ctx, tx, *previous, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedChapter *entity.Chapter
+	err = cs.saveWithSlug(ctx, tx, r.Title, *previous, func(slug string) error {
+		c.Slug = slug
+
+		updated, err := cs.chapterRepository.Update(ctx, tx, r.UserID, r.StorySlug, r.ChapterSlug, c)
+		updatedChapter = updated
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.saveSlugHistory(ctx, tx, *previous, c)
 	if err != nil {
 		return nil, err
 	}
@@ -184,35 +332,76 @@ func (cs *chapterService) EditChapter(ctx context.Context, r model.UpdateChapter
 		Title:         chapter.Title,
 		Slug:          chapter.Slug,
 		Body:          chapter.Body,
+		BodyFormat:    chapter.BodyFormat,
+		BodyHTML:      chapter.HTML(),
 		AuthorComment: chapter.AuthorComment,
 		WordCounts:    chapter.WordCounts,
 		ReadingTime:   chapter.ReadingTime,
 		IsPublished:   chapter.IsPublished,
+		PublishAt:     time.NullUnixToTime(chapter.PublishAt),
 		CreatedAt:     time.UnixToTime(chapter.CreatedAt),
 		UpdatedAt:     time.UnixToTime(chapter.UpdatedAt),
 	}, nil
 }
 
-func (cs *chapterService) GetChapterByStorySlugAndChapterSlug(ctx context.Context, userID uint64, storySlug string, chapterSlug string) (*model.ChapterResponseByStorySlugAndChapterSlug, error) {
+// Get single chapter visible to the viewer, the hidden chapter is
+// treated as not found so the draft existence is not leaked.
+// The body is returned as the source or the rendered HTML based on the format.
+func (cs *chapterService) GetChapterByStorySlugAndChapterSlug(ctx context.Context, viewer entity.Viewer, storySlug string, chapterSlug string, format string) (*model.ChapterResponseByStorySlugAndChapterSlug, error) {
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
-	story, err := cs.storyRepository.FindBySlugAndUserID(ctx, tx, storySlug, userID)
+	if format == "" {
+		format = model.FORMAT_SOURCE
+	}
+
+	if format != model.FORMAT_SOURCE && format != model.FORMAT_HTML {
+		return nil, exception.ErrInvalidFormat
+	}
+
+	chapter, err := cs.chapterRepository.FindBySlug(ctx, tx, storySlug, chapterSlug)
+	if errors.Is(err, exception.ErrChapterNotFound) {
+		return nil, cs.movedChapter(ctx, tx, viewer, storySlug, chapterSlug)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	chapter, err := cs.chapterRepository.FindByStorySlugAndChapterSlug(ctx, tx, userID, storySlug, chapterSlug)
+	if !viewer.CanSeeChapter(*chapter, chapter.Story) {
+		return nil, exception.ErrChapterNotFound
+	}
+
+	if !viewer.CanReadAdultStory(chapter.Story) {
+		return nil, storyexception.ErrAdultContentRestricted
+	}
+
+	likes, err := cs.chapterRepository.CountChapterLikesByChapterID(ctx, tx, chapter.Id)
 	if err != nil {
 		return nil, err
 	}
 
+	liked, err := cs.chapterRepository.CheckIfChapterLiked(ctx, tx, chapter.Id, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := cs.chapterRepository.FindPreviousChapter(ctx, tx, *chapter, viewer)
+	if err != nil && !errors.Is(err, exception.ErrChapterNotFound) {
+		return nil, err
+	}
+
+	next, err := cs.chapterRepository.FindNextChapter(ctx, tx, *chapter, viewer)
+	if err != nil && !errors.Is(err, exception.ErrChapterNotFound) {
+		return nil, err
+	}
+
 	return &model.ChapterResponseByStorySlugAndChapterSlug{
 		Id:      chapter.Id,
-		StoryID: story.Id,
+		StoryID: chapter.StoryID,
 		Story: storymodel.StoryResponseByChapter{
 			Id:    chapter.Story.Id,
 			Slug:  chapter.Story.Slug,
@@ -225,73 +414,558 @@ func (cs *chapterService) GetChapterByStorySlugAndChapterSlug(ctx context.Contex
 		},
 		Title:         chapter.Title,
 		Slug:          chapter.Slug,
-		Body:          chapter.Body,
+		Body:          chapterBody(chapter, format),
+		BodyFormat:    chapter.BodyFormat,
+		Format:        format,
 		AuthorComment: chapter.AuthorComment,
+		Likes:         *likes,
+		LikedByMe:     *liked,
 		ReadingTime:   chapter.ReadingTime,
+		Position:      chapter.Position,
+		Previous:      navigationResponse(previous),
+		Next:          navigationResponse(next),
 		UpdatedAt:     time.UnixToTime(chapter.UpdatedAt),
 	}, nil
 }
 
-func (cs *chapterService) RemoveChapter(ctx context.Context, userID uint64, chapterID string) (*model.DeletedChapterResponse, error) {
+// Reorder the chapters of the story. The request must contain every
+// chapter of the story exactly once, otherwise throwing an err invalid
+// chapter order. The chapters are renumbered from one on single transaction.
+func (cs *chapterService) ReorderChapters(ctx context.Context, r model.ReorderChapterRequest) (*[]model.ChapterResponseByNavigation, error) {
+	tx, err := cs.beginLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	sId, err := strconv.ParseUint(r.StoryID, 10, 64)
+	if err != nil {
+		return nil, storyexception.ErrStoryNotFound
+	}
+
+	story, err := cs.storyRepository.FindByID(ctx, tx, sId)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the author can reorder the chapters
+	if story.UserID != r.UserID {
+		return nil, storyexception.ErrStoryNotFound
+	}
+
+	// no chapter is added while the chapters are reordered
+	err = cs.storyRepository.LockByID(ctx, tx, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the author see every chapter, including the drafts
+	chapters, err := cs.chapterRepository.FindAllChapterByStorySlug(ctx, tx, story.Slug, entity.Viewer{UserID: r.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.ChapterIDs) != len(*chapters) {
+		return nil, exception.ErrInvalidChapterOrder
+	}
+
+	byID := make(map[uint64]entity.Chapter)
+	for _, c := range *chapters {
+		byID[c.Id] = c
+	}
+
+	var chaptersResponse []model.ChapterResponseByNavigation
+	for i, chapterID := range r.ChapterIDs {
+		id, err := strconv.ParseUint(chapterID, 10, 64)
+		if err != nil {
+			return nil, exception.ErrInvalidChapterOrder
+		}
+
+		c, ok := byID[id]
+		if !ok {
+			return nil, exception.ErrInvalidChapterOrder
+		}
+
+		// prevent the same chapter ordered twice
+		delete(byID, id)
+
+		c.Position = uint64(i + 1)
+		err = cs.chapterRepository.UpdatePosition(ctx, tx, c.Id, c.Position)
+		if err != nil {
+			return nil, err
+		}
+
+		chaptersResponse = append(chaptersResponse, *navigationResponse(&c))
+	}
+
+	return &chaptersResponse, nil
+}
+
+// Get revisions of the chapter, only the author can see the revisions.
+func (cs *chapterService) GetRevisions(ctx context.Context, userID uint64, storyID string, chapterID string, r pagination.Request) (*[]model.RevisionResponse, *pagination.Meta, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chapter, err := cs.findOwnedChapter(ctx, tx, userID, storyID, chapterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revisions, err := cs.chapterRepository.FindAllRevisionByChapterID(ctx, tx, chapter.Id, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := cs.chapterRepository.CountRevisionByChapterID(ctx, tx, chapter.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *revisions)
+
+	var cursors []pagination.Cursor
+	var revisionsResponse []model.RevisionResponse
+	for _, rev := range rows {
+		cursors = append(cursors, pagination.Cursor{Value: rev.CreatedAt, ID: rev.Id})
+		revisionsResponse = append(revisionsResponse, revisionResponse(rev))
+	}
+
+	return &revisionsResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+// Get single revision of the chapter with the body.
+func (cs *chapterService) GetRevision(ctx context.Context, userID uint64, storyID string, chapterID string, revisionID string) (*model.RevisionResponse, error) {
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
+	chapter, err := cs.findOwnedChapter(ctx, tx, userID, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := cs.findRevision(ctx, tx, chapter.Id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := revisionResponse(*revision)
+
+	return &response, nil
+}
+
+// Create unified diff of the chapter body between two revisions.
+// When To is empty, the revision is compared with the current chapter.
+func (cs *chapterService) DiffRevisions(ctx context.Context, r model.DiffRevisionRequest) (*model.DiffRevisionResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.To == "" {
+		r.To = REVISION_CURRENT
+	}
+
+	chapter, err := cs.findOwnedChapter(ctx, tx, r.UserID, r.StoryID, r.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// read the body of the revision, or the chapter itself for current
+	body := func(revisionID string) (string, error) {
+		if revisionID == REVISION_CURRENT {
+			return chapter.Body, nil
+		}
+
+		revision, err := cs.findRevision(ctx, tx, chapter.Id, revisionID)
+		if err != nil {
+			return "", err
+		}
+
+		return revision.Body, nil
+	}
+
+	from, err := body(r.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := body(r.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DiffRevisionResponse{
+		From: r.From,
+		To:   r.To,
+		Diff: diff.Unified(revisionLabel(r.From), revisionLabel(r.To), from, to, diff.DEFAULT_CONTEXT),
+	}, nil
+}
+
+// Restore the revision as a new edit. The current content is saved as
+// a revision first, so the restore itself can be reverted.
+func (cs *chapterService) RestoreRevision(ctx context.Context, userID uint64, storyID string, chapterID string, revisionID string) (*model.UpdatedChapterResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	chapter, err := cs.findOwnedChapter(ctx, tx, userID, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := cs.findRevision(ctx, tx, chapter.Id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *chapter
+	c.Title = revision.Title
+	c.Body = revision.Body
+	c.BodyFormat = revision.BodyFormat
+	c.AuthorComment = revision.AuthorComment
+	c.CalculateMetrics()
+	c.RenderBody()
+	c.UpdatedAt = time.CurrentTimeToUnixTimestamp()
+
+	err = cs.saveRevision(ctx, tx, *chapter, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var restoredChapter *entity.Chapter
+	err = cs.saveWithSlug(ctx, tx, revision.Title, *chapter, func(slug string) error {
+		c.Slug = slug
+
+		restored, err := cs.chapterRepository.UpdateContent(ctx, tx, c)
+		restoredChapter = restored
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.saveSlugHistory(ctx, tx, *chapter, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdatedChapterResponse{
+		Id:            restoredChapter.Id,
+		StoryID:       restoredChapter.StoryID,
+		Title:         restoredChapter.Title,
+		Slug:          restoredChapter.Slug,
+		Body:          restoredChapter.Body,
+		BodyFormat:    restoredChapter.BodyFormat,
+		BodyHTML:      restoredChapter.BodyHTML,
+		AuthorComment: restoredChapter.AuthorComment,
+		WordCounts:    restoredChapter.WordCounts,
+		ReadingTime:   restoredChapter.ReadingTime,
+		IsPublished:   restoredChapter.IsPublished,
+		CreatedAt:     time.UnixToTime(restoredChapter.CreatedAt),
+		UpdatedAt:     time.UnixToTime(restoredChapter.UpdatedAt),
+	}, nil
+}
+
+// Snapshot the previous chapter content before it is replaced by the
+// updated chapter. Nothing is saved when the content is not changed.
+func (cs *chapterService) saveRevision(ctx context.Context, tx *sql.Tx, previous entity.Chapter, updated entity.Chapter) error {
+	revision := entity.ChapterRevision{
+		ChapterID:     previous.Id,
+		Title:         previous.Title,
+		Body:          previous.Body,
+		BodyFormat:    previous.BodyFormat,
+		AuthorComment: previous.AuthorComment,
+		CreatedAt:     time.CurrentTimeToUnixTimestamp(),
+	}
+
+	if revision.IsSameContent(updated) {
+		return nil
+	}
+
+	_, err := cs.chapterRepository.SaveRevision(ctx, tx, revision)
+
+	return err
+}
+
+// Save the chapter with the slug generated from the title. The slug taken by
+// another chapter since it was generated is excluded, then saved again.
+func (cs *chapterService) saveWithSlug(ctx context.Context, tx *sql.Tx, title string, c entity.Chapter, save func(slug string) error) error {
+	var excluded []string
+	for i := 0; i < slugpkg.MAX_ATTEMPTS; i++ {
+		slug, err := cs.generateSlug(ctx, tx, title, c, excluded)
+		if err != nil {
+			return err
+		}
+
+		err = save(slug)
+		if !errors.Is(err, exception.ErrSlugTaken) {
+			return err
+		}
+
+		excluded = append(excluded, slug)
+	}
+
+	return exception.ErrSlugTaken
+}
+
+// Generate slug of the title that is unique among the chapters of the story,
+// including the previous slugs of the renamed chapters and the excluded slugs.
+// The current slug is kept when it is still based on the title.
+func (cs *chapterService) generateSlug(ctx context.Context, tx *sql.Tx, title string, c entity.Chapter, excluded []string) (string, error) {
+	base := c.ToSlug(title)
+	if c.Slug != "" && slugpkg.HasBase(c.Slug, base) {
+		return c.Slug, nil
+	}
+
+	taken, err := cs.chapterRepository.FindSlugsByBase(ctx, tx, c.StoryID, base, c.Id)
+	if err != nil {
+		return "", err
+	}
+
+	return slugpkg.Unique(base, append(*taken, excluded...)), nil
+}
+
+// Keep the previous slug when the chapter is renamed,
+// so the previous slug keeps referring to the chapter.
+func (cs *chapterService) saveSlugHistory(ctx context.Context, tx *sql.Tx, previous entity.Chapter, updated entity.Chapter) error {
+	if previous.Slug == updated.Slug {
+		return nil
+	}
+
+	return cs.chapterRepository.SaveSlugHistory(ctx, tx, updated, previous.Slug, updated.UpdatedAt)
+}
+
+// Find the chapter renamed from the slugs, either the story or the chapter
+// may have been renamed. The visible chapter is redirected to the current
+// slugs, otherwise the chapter is not found.
+func (cs *chapterService) movedChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, storySlug string, chapterSlug string) error {
+	story, err := cs.storyRepository.FindBySlug(ctx, tx, storySlug)
+	if errors.Is(err, storyexception.ErrStoryNotFound) {
+		story, err = cs.storyRepository.FindByPreviousSlug(ctx, tx, storySlug)
+	}
+
+	if errors.Is(err, storyexception.ErrStoryNotFound) {
+		return exception.ErrChapterNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	slug, err := cs.chapterRepository.FindPreviousSlug(ctx, tx, story.Id, chapterSlug)
+	if errors.Is(err, exception.ErrChapterNotFound) {
+		// only the story was renamed
+		slug = &chapterSlug
+	} else if err != nil {
+		return err
+	}
+
+	chapter, err := cs.chapterRepository.FindBySlug(ctx, tx, story.Slug, *slug)
+	if err != nil {
+		return err
+	}
+
+	if !viewer.CanSeeChapter(*chapter, chapter.Story) {
+		return exception.ErrChapterNotFound
+	}
+
+	return &storyexception.SlugMovedError{StorySlug: chapter.Story.Slug, ChapterSlug: chapter.Slug}
+}
+
+// Find the chapter of the story that owned by the user.
+func (cs *chapterService) findOwnedChapter(ctx context.Context, tx *sql.Tx, userID uint64, storyID string, chapterID string) (*entity.Chapter, error) {
+	chapter, err := cs.findChapterByStoryID(ctx, tx, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chapter.Story.UserID != userID {
+		return nil, exception.ErrChapterNotFound
+	}
+
+	return chapter, nil
+}
+
+// Find the revision by ID from the path.
+func (cs *chapterService) findRevision(ctx context.Context, tx *sql.Tx, chapterID uint64, revisionID string) (*entity.ChapterRevision, error) {
+	id, err := strconv.ParseUint(revisionID, 10, 64)
+	if err != nil {
+		return nil, exception.ErrRevisionNotFound
+	}
+
+	return cs.chapterRepository.FindRevisionByID(ctx, tx, chapterID, id)
+}
+
+func revisionLabel(revisionID string) string {
+	if revisionID == REVISION_CURRENT {
+		return REVISION_CURRENT
+	}
+
+	return "revision/" + revisionID
+}
+
+func revisionResponse(r entity.ChapterRevision) model.RevisionResponse {
+	return model.RevisionResponse{
+		Id:            r.Id,
+		ChapterID:     r.ChapterID,
+		Title:         r.Title,
+		Body:          r.Body,
+		BodyFormat:    r.BodyFormat,
+		AuthorComment: r.AuthorComment,
+		CreatedAt:     time.UnixToTime(r.CreatedAt),
+	}
+}
+
+// Get the body of the chapter in the requested format.
+func chapterBody(c *entity.Chapter, format string) string {
+	if format == model.FORMAT_HTML {
+		return c.HTML()
+	}
+
+	return c.Body
+}
+
+// Get the body format of the request, falling back to the given format when empty.
+func bodyFormat(format string, fallback string) string {
+	if format == "" {
+		return fallback
+	}
+
+	return format
+}
+
+func navigationResponse(c *entity.Chapter) *model.ChapterResponseByNavigation {
+	if c == nil {
+		return nil
+	}
+
+	return &model.ChapterResponseByNavigation{
+		Id:       c.Id,
+		Title:    c.Title,
+		Slug:     c.Slug,
+		Position: c.Position,
+	}
+}
+
+func (cs *chapterService) RemoveChapter(ctx context.Context, userID uint64, chapterID string) (*model.DeletedChapterResponse, error) {
+	tx, err := cs.beginLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
 	cId, err := strconv.Atoi(chapterID)
 
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = cs.chapterRepository.FindByID(ctx, tx, uint64(cId))
+	chapter, err := cs.chapterRepository.FindByID(ctx, tx, uint64(cId))
 	if err != nil {
 		return nil, err
 	}
 
+	// serialize with adding and reordering the chapters of the story, the chapter
+	// is read again since its position may have changed before the story is locked
+	if chapter.Story.UserID == userID {
+		err = cs.storyRepository.LockByID(ctx, tx, chapter.StoryID)
+		if err != nil {
+			return nil, err
+		}
+
+		chapter, err = cs.chapterRepository.FindByID(ctx, tx, uint64(cId))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = cs.chapterRepository.Delete(ctx, tx, userID, uint64(cId))
 	if err != nil {
 		return nil, err
 	}
 
+	// close the gap, the chapter is only deleted by the author
+	if chapter.Story.UserID == userID {
+		err = cs.chapterRepository.ShiftPositionAfter(ctx, tx, chapter.StoryID, chapter.Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &model.DeletedChapterResponse{
 		Status: true,
 	}, nil
 }
 
-func (cs *chapterService) CalculateReadingTimeByChapters(ctx context.Context, chapters []entity.Chapter) (string, error) {
-	var wordCountTotal uint64
+// Begin the transaction that locks the story to position its chapters.
+// The isolation is read committed, so the chapters read after the story is
+// locked include the chapters committed while waiting for the lock.
+func (cs *chapterService) beginLocked(ctx context.Context) (*sql.Tx, error) {
+	return cs.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+}
 
+// Calculating total reading time in seconds of the chapters.
+func (cs *chapterService) CalculateReadingTimeByChapters(ctx context.Context, chapters []entity.Chapter) (uint64, error) {
+	var readingTime uint64
 	for _, c := range chapters {
-		wordCountTotal += c.WordCounts
+		readingTime += c.ReadingTime
 	}
 
-	result := (wordCountTotal / 200)
-
-	return fmt.Sprintf("%s Minutes", strconv.Itoa(int(result))), nil
+	return readingTime, nil
 }
 
-func (cs *chapterService) GetAllChapterByStorySlug(ctx context.Context, storySlug string) (*[]model.ChapterResponseBySlug, error) {
+func (cs *chapterService) GetAllChapterByStorySlug(ctx context.Context, viewer entity.Viewer, storySlug string) (*[]model.ChapterResponseBySlug, error) {
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
-	chapters, err := cs.chapterRepository.FindAllChapterByStorySlug(ctx, tx, storySlug)
+	chapters, err := cs.chapterRepository.FindAllChapterByStorySlug(ctx, tx, storySlug, viewer)
 	if err != nil {
 		return nil, err
 	}
 
 	var chaptersResponse []model.ChapterResponseBySlug
 	for _, c := range *chapters {
+		chapterComments, err := cs.chapterRepository.CountChapterCommentsByChapterID(ctx, tx, c.Id)
+		if err != nil {
+			return nil, err
+		}
+
 		chapterResponse := model.ChapterResponseBySlug{
 			Id:         c.Id,
 			StoryID:    c.StoryID,
 			Title:      c.Title,
 			Slug:       c.Slug,
+			Position:   c.Position,
 			WordCounts: c.WordCounts,
+			Comments:   *chapterComments,
 		}
 
 		chaptersResponse = append(chaptersResponse, chapterResponse)
@@ -300,33 +974,64 @@ func (cs *chapterService) GetAllChapterByStorySlug(ctx context.Context, storySlu
 	return &chaptersResponse, nil
 }
 
-func (cs *chapterService) GetAllChapterByStoryID(ctx context.Context, storyID string) (*[]model.ChapterResponseBySlug, error) {
+func (cs *chapterService) GetAllChapterByStoryID(ctx context.Context, viewer entity.Viewer, storyID string, r pagination.Request) (*[]model.ChapterResponseBySlug, *pagination.Meta, error) {
 	tx, err := cs.db.Begin()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	sId, err := strconv.Atoi(storyID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	_, err = cs.storyRepository.FindByID(ctx, tx, uint64(sId))
+	story, err := cs.storyRepository.FindByID(ctx, tx, uint64(sId))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	chapters, err := cs.chapterRepository.FindAllChapterByStoryID(ctx, tx, uint64(sId))
+	if !viewer.CanSeeStory(*story) {
+		return nil, nil, storyexception.ErrStoryNotFound
+	}
+
+	if !viewer.CanReadAdultStory(*story) {
+		return nil, nil, storyexception.ErrAdultContentRestricted
+	}
+
+	chapters, err := cs.chapterRepository.FindAllChapterByStoryID(ctx, tx, uint64(sId), viewer, *page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	total, err := cs.chapterRepository.CountChapterByStoryID(ctx, tx, uint64(sId), viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *chapters)
+
+	var cursors []pagination.Cursor
 	var chaptersResponse []model.ChapterResponseBySlug
-	for _, c := range *chapters {
+	for _, c := range rows {
 		chapterLikes, err := cs.chapterRepository.CountChapterLikesByChapterID(ctx, tx, c.Id)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		liked, err := cs.chapterRepository.CheckIfChapterLiked(ctx, tx, c.Id, viewer.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chapterComments, err := cs.chapterRepository.CountChapterCommentsByChapterID(ctx, tx, c.Id)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		chapterResponse := model.ChapterResponseBySlug{
@@ -334,14 +1039,33 @@ func (cs *chapterService) GetAllChapterByStoryID(ctx context.Context, storyID st
 			StoryID:    c.StoryID,
 			Title:      c.Title,
 			Slug:       c.Slug,
+			Position:   c.Position,
 			WordCounts: c.WordCounts,
 			Likes:      *chapterLikes,
+			LikedByMe:  *liked,
+			Comments:   *chapterComments,
 		}
 
 		chaptersResponse = append(chaptersResponse, chapterResponse)
+		cursors = append(cursors, pagination.Cursor{Value: c.Position, ID: c.Id})
 	}
 
-	return &chaptersResponse, nil
+	return &chaptersResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+// Parse the scheduled publish time of the request.
+// Scheduled chapter is kept as draft until the scheduler publish it.
+func schedulePublish(publishAt string) (sql.NullInt64, error) {
+	if publishAt == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	t, err := time.ParseToUnixTimestamp(storymodel.PUBLISH_AT_LAYOUT, publishAt)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+
+	return sql.NullInt64{Int64: int64(t), Valid: true}, nil
 }
 
 func NewService(cr chapter.ChapterRepository, sr story.StoryRepository, db *sql.DB) ChapterService {
@@ -5,15 +5,22 @@ import (
 
 	"github.com/mrizkimaulidan/storial/internal/entity"
 	model "github.com/mrizkimaulidan/storial/internal/model/chapter"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type ChapterService interface {
 	AddChapter(ctx context.Context, r model.CreateChapterRequest) (*model.CreatedChapterdResponse, error)
 	EditChapter(ctx context.Context, r model.UpdateChapterRequest) (*model.UpdatedChapterResponse, error)
-	GetChapterByStorySlugAndChapterSlug(ctx context.Context, userID uint64, storySlug string, chapterSlug string) (*model.ChapterResponseByStorySlugAndChapterSlug, error)
+	GetChapterByStorySlugAndChapterSlug(ctx context.Context, viewer entity.Viewer, storySlug string, chapterSlug string, format string) (*model.ChapterResponseByStorySlugAndChapterSlug, error)
+	ReorderChapters(ctx context.Context, r model.ReorderChapterRequest) (*[]model.ChapterResponseByNavigation, error)
+	GetRevisions(ctx context.Context, userID uint64, storyID string, chapterID string, r pagination.Request) (*[]model.RevisionResponse, *pagination.Meta, error)
+	GetRevision(ctx context.Context, userID uint64, storyID string, chapterID string, revisionID string) (*model.RevisionResponse, error)
+	DiffRevisions(ctx context.Context, r model.DiffRevisionRequest) (*model.DiffRevisionResponse, error)
+	RestoreRevision(ctx context.Context, userID uint64, storyID string, chapterID string, revisionID string) (*model.UpdatedChapterResponse, error)
 	RemoveChapter(ctx context.Context, userID uint64, chapterID string) (*model.DeletedChapterResponse, error)
-	CalculateReadingTimeByChapters(ctx context.Context, chapters []entity.Chapter) (string, error)
-	GetAllChapterByStorySlug(ctx context.Context, storySlug string) (*[]model.ChapterResponseBySlug, error)
-	GetAllChapterByStoryID(ctx context.Context, storyID string) (*[]model.ChapterResponseBySlug, error)
-	LikeChapter(ctx context.Context, storyID string, chapterID string, userID uint64) (*model.LikedChapterResponse, error)
+	CalculateReadingTimeByChapters(ctx context.Context, chapters []entity.Chapter) (uint64, error)
+	GetAllChapterByStorySlug(ctx context.Context, viewer entity.Viewer, storySlug string) (*[]model.ChapterResponseBySlug, error)
+	GetAllChapterByStoryID(ctx context.Context, viewer entity.Viewer, storyID string, r pagination.Request) (*[]model.ChapterResponseBySlug, *pagination.Meta, error)
+	LikeChapter(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string) (*model.LikedChapterResponse, error)
+	UnlikeChapter(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string) (*model.LikedChapterResponse, error)
 }
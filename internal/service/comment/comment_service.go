@@ -0,0 +1,297 @@
+package comment
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/comment"
+	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/comment"
+	chapterexception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/comment"
+	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+type commentService struct {
+	commentRepository comment.CommentRepository
+	chapterRepository chapter.ChapterRepository
+	db                *sql.DB
+}
+
+func (cs *commentService) AddComment(ctx context.Context, viewer entity.Viewer, r model.CreateCommentRequest) (*model.CommentResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	chapter, err := cs.findVisibleChapter(ctx, tx, viewer, r.StoryID, r.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := entity.Comment{
+		ChapterID: chapter.Id,
+		UserID:    r.UserID,
+		Body:      r.Body,
+		CreatedAt: time.CurrentTimeToUnixTimestamp(),
+		UpdatedAt: time.CurrentTimeToUnixTimestamp(),
+	}
+
+	if r.ParentID != "" {
+		parent, err := cs.findComment(ctx, tx, chapter.Id, r.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		// replying to a reply is attached to the top level comment,
+		// so the thread is only one level deep
+		c.ParentID = sql.NullInt64{Int64: int64(parent.Id), Valid: true}
+		if parent.IsReply() {
+			c.ParentID = parent.ParentID
+		}
+	}
+
+	createdComment, err := cs.commentRepository.Save(ctx, tx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	commentResponse := toCommentResponse(*createdComment)
+
+	return &commentResponse, nil
+}
+
+func (cs *commentService) EditComment(ctx context.Context, viewer entity.Viewer, r model.UpdateCommentRequest) (*model.CommentResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	chapter, err := cs.findVisibleChapter(ctx, tx, viewer, r.StoryID, r.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cs.findComment(ctx, tx, chapter.Id, r.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the owner can edit the comment
+	if c.UserID != r.UserID {
+		return nil, exception.ErrNotAllowedToEdit
+	}
+
+	c.Body = r.Body
+	c.UpdatedAt = time.CurrentTimeToUnixTimestamp()
+
+	updatedComment, err := cs.commentRepository.Update(ctx, tx, *c)
+	if err != nil {
+		return nil, err
+	}
+
+	commentResponse := toCommentResponse(*updatedComment)
+
+	return &commentResponse, nil
+}
+
+func (cs *commentService) RemoveComment(ctx context.Context, r model.DeleteCommentRequest) (*model.DeletedCommentResponse, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	chapter, err := cs.findChapter(ctx, tx, r.StoryID, r.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cs.findComment(ctx, tx, chapter.Id, r.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the owner and the story author can delete the comment
+	if c.UserID != r.UserID && chapter.Story.UserID != r.UserID {
+		return nil, exception.ErrNotAllowedToDelete
+	}
+
+	err = cs.commentRepository.Delete(ctx, tx, c.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletedCommentResponse{
+		Status: true,
+	}, nil
+}
+
+func (cs *commentService) GetAllCommentByChapterID(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string, r pagination.Request) (*[]model.CommentResponse, *pagination.Meta, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chapter, err := cs.findVisibleChapter(ctx, tx, viewer, storyID, chapterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comments, err := cs.commentRepository.FindAllByChapterID(ctx, tx, chapter.Id, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := cs.commentRepository.CountCommentByChapterID(ctx, tx, chapter.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *comments)
+
+	var parentIDs []uint64
+	for _, c := range rows {
+		parentIDs = append(parentIDs, c.Id)
+	}
+
+	replies, err := cs.commentRepository.FindRepliesByParentIDs(ctx, tx, parentIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repliesByParentID := make(map[uint64][]model.CommentResponse)
+	for _, reply := range *replies {
+		parentID := uint64(reply.ParentID.Int64)
+		repliesByParentID[parentID] = append(repliesByParentID[parentID], toCommentResponse(reply))
+	}
+
+	var cursors []pagination.Cursor
+	var commentsResponse []model.CommentResponse
+	for _, c := range rows {
+		commentResponse := toCommentResponse(c)
+		commentResponse.Replies = repliesByParentID[c.Id]
+
+		commentsResponse = append(commentsResponse, commentResponse)
+		cursors = append(cursors, pagination.Cursor{Value: c.CreatedAt, ID: c.Id})
+	}
+
+	return &commentsResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+// Find the chapter and make sure it belongs to the story.
+func (cs *commentService) findChapter(ctx context.Context, tx *sql.Tx, storyID string, chapterID string) (*entity.Chapter, error) {
+	sId, err := strconv.Atoi(storyID)
+	if err != nil {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	cId, err := strconv.Atoi(chapterID)
+	if err != nil {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	chapter, err := cs.chapterRepository.FindByID(ctx, tx, uint64(cId))
+	if err != nil {
+		return nil, err
+	}
+
+	if chapter.StoryID != uint64(sId) {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	return chapter, nil
+}
+
+// Find the chapter of the story visible to the viewer, the hidden chapter
+// is treated as not found so the draft existence is not leaked.
+// Chapter of adult story is restricted to the viewer allowed to read it.
+func (cs *commentService) findVisibleChapter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, storyID string, chapterID string) (*entity.Chapter, error) {
+	chapter, err := cs.findChapter(ctx, tx, storyID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanSeeChapter(*chapter, chapter.Story) {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	if !viewer.CanReadAdultStory(chapter.Story) {
+		return nil, storyexception.ErrAdultContentRestricted
+	}
+
+	return chapter, nil
+}
+
+// Find the comment and make sure it belongs to the chapter.
+func (cs *commentService) findComment(ctx context.Context, tx *sql.Tx, chapterID uint64, commentID string) (*entity.Comment, error) {
+	id, err := strconv.Atoi(commentID)
+	if err != nil {
+		return nil, exception.ErrCommentNotFound
+	}
+
+	c, err := cs.commentRepository.FindByID(ctx, tx, uint64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ChapterID != chapterID {
+		return nil, exception.ErrCommentNotFound
+	}
+
+	return c, nil
+}
+
+func toCommentResponse(c entity.Comment) model.CommentResponse {
+	var parentID *uint64
+	if c.IsReply() {
+		id := uint64(c.ParentID.Int64)
+		parentID = &id
+	}
+
+	return model.CommentResponse{
+		Id:        c.Id,
+		ChapterID: c.ChapterID,
+		ParentID:  parentID,
+		User: usermodel.UserResponseByChapter{
+			Id:       c.User.Id,
+			Name:     c.User.Name,
+			Username: c.User.Username,
+		},
+		Body:      c.Body,
+		CreatedAt: time.UnixToTime(c.CreatedAt),
+		UpdatedAt: time.UnixToTime(c.UpdatedAt),
+	}
+}
+
+func NewService(commentRepository comment.CommentRepository, chapterRepository chapter.ChapterRepository, db *sql.DB) CommentService {
+	return &commentService{
+		commentRepository: commentRepository,
+		chapterRepository: chapterRepository,
+		db:                db,
+	}
+}
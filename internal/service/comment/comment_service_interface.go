@@ -0,0 +1,16 @@
+package comment
+
+import (
+	"context"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/comment"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type CommentService interface {
+	AddComment(ctx context.Context, viewer entity.Viewer, r model.CreateCommentRequest) (*model.CommentResponse, error)
+	EditComment(ctx context.Context, viewer entity.Viewer, r model.UpdateCommentRequest) (*model.CommentResponse, error)
+	RemoveComment(ctx context.Context, r model.DeleteCommentRequest) (*model.DeletedCommentResponse, error)
+	GetAllCommentByChapterID(ctx context.Context, viewer entity.Viewer, storyID string, chapterID string, r pagination.Request) (*[]model.CommentResponse, *pagination.Meta, error)
+}
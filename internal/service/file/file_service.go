@@ -1,79 +1,62 @@
 package file
 
 import (
-	"fmt"
-	"io"
-	"mime/multipart"
-	"os"
-	"path/filepath"
-
-	"github.com/mrizkimaulidan/storial/pkg/time"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrizkimaulidan/storial/internal/config"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/file"
 )
 
-type fileService struct {
-	Path     string
-	Filename string
-}
-
-func (fs *fileService) CreateDir() error {
-	err := os.MkdirAll(fs.Path, os.ModePerm)
-
-	return err
-}
-
-func (fs *fileService) SetFilename(filename string) {
-	fs.Filename = filename
-}
-
-func (fs *fileService) Get(fullPath string) ([]byte, error) {
-	fileBytes, err := os.ReadFile(fullPath)
-	if err != nil {
-		return nil, err
-	}
-
-	return fileBytes, nil
-}
-
-func (fs *fileService) GetFullPath() string {
-	return fs.Path + "/" + fs.Filename
-}
-
-func (fs *fileService) Upload(f multipart.File, fileheader *multipart.FileHeader) (string, error) {
-	filename := fmt.Sprintf("%d%s", time.CurrentTimeToUnixTimestamp(), filepath.Ext(fileheader.Filename))
-
-	fs.SetFilename(filename)
-
-	dst := fs.GetFullPath()
-
-	err := fs.CreateDir()
-	if err != nil {
-		return "", err
-	}
+var (
+	DRIVER_LOCAL = "local"
+	DRIVER_S3    = "s3"
+)
 
-	file, err := os.Create(dst)
-	if err != nil {
-		return "", err
+// Clean the file key, the key cannot escape the storage root.
+func cleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", exception.ErrInvalidFileKey
 	}
-	defer file.Close()
 
-	_, err = io.Copy(file, f)
-	if err != nil {
-		return "", err
+	return cleaned, nil
+}
+
+// Create storage driver based on STORAGE_DRIVER config.
+// If the driver is unknown, will throwing an fatal error.
+func NewService() FileService {
+	c := config.New().GetConfig()
+
+	switch c.STORAGE_DRIVER {
+	case DRIVER_LOCAL:
+		return NewLocalService(c.STORAGE_LOCAL_PATH, c.STORAGE_LOCAL_URL)
+	case DRIVER_S3:
+		expiry, err := strconv.Atoi(c.STORAGE_URL_EXPIRY)
+		if err != nil {
+			log.Fatalln("invalid STORAGE_URL_EXPIRY", err)
+		}
+
+		pathStyle, err := strconv.ParseBool(c.S3_USE_PATH_STYLE)
+		if err != nil {
+			log.Fatalln("invalid S3_USE_PATH_STYLE", err)
+		}
+
+		return NewS3Service(S3Options{
+			Endpoint:  c.S3_ENDPOINT,
+			Region:    c.S3_REGION,
+			Bucket:    c.S3_BUCKET,
+			AccessKey: c.S3_ACCESS_KEY,
+			SecretKey: c.S3_SECRET_KEY,
+			PublicURL: c.S3_PUBLIC_URL,
+			PathStyle: pathStyle,
+			URLExpiry: time.Duration(expiry) * time.Second,
+		})
 	}
 
-	return filename, nil
-}
-
-func (fs *fileService) RemoveFile(filename string) {
-	os.Remove(fs.GetPath() + "/" + filename)
-}
-
-func (fs *fileService) GetPath() string {
-	return fs.Path
-}
-
-func NewService(path string) FileService {
-	return &fileService{
-		Path: path,
-	}
+	log.Fatalln(exception.ErrUnknownStorageDriver, c.STORAGE_DRIVER)
+	return nil
 }
@@ -1,13 +1,16 @@
 package file
 
-import "mime/multipart"
+import (
+	"context"
+	"io"
+)
 
+// Storage driver. The key is slash separated path relative to the
+// storage root, for example cover/1662039000000.jpg.
 type FileService interface {
-	Get(fullPath string) ([]byte, error)
-	GetPath() string
-	CreateDir() error
-	RemoveFile(filename string)
-	Upload(f multipart.File, fileheader *multipart.FileHeader) (string, error)
-	SetFilename(filename string)
-	GetFullPath() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(ctx context.Context, key string) (string, error)
 }
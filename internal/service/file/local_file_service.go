@@ -0,0 +1,114 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/file"
+)
+
+// Storage driver that saves files to the local disk.
+// Only usable when running single API instance.
+type localFileService struct {
+	Root    string
+	BaseURL string
+}
+
+func (fs *localFileService) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst, err := fs.fullPath(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (fs *localFileService) Get(ctx context.Context, key string) ([]byte, error) {
+	rc, err := fs.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func (fs *localFileService) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	src, err := fs.fullPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, exception.ErrFileNotFound
+		}
+
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (fs *localFileService) Delete(ctx context.Context, key string) error {
+	src, err := fs.fullPath(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(src)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Get public URL of the file. The files are served by the server
+// under the base URL, no signing needed.
+func (fs *localFileService) URL(ctx context.Context, key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(fs.BaseURL, "/") + "/" + cleaned, nil
+}
+
+// Get full path of the key on local disk.
+func (fs *localFileService) fullPath(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(fs.Root, filepath.FromSlash(cleaned)), nil
+}
+
+func NewLocalService(root string, baseURL string) FileService {
+	return &localFileService{
+		Root:    root,
+		BaseURL: baseURL,
+	}
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/file"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+		err  error
+	}{
+		{key: "cover/full/a.jpg", want: "cover/full/a.jpg"},
+		{key: "/cover/full/a.jpg", want: "cover/full/a.jpg"},
+		{key: "", err: exception.ErrInvalidFileKey},
+		{key: "/", err: exception.ErrInvalidFileKey},
+		{key: "..", err: exception.ErrInvalidFileKey},
+		{key: "../secret", err: exception.ErrInvalidFileKey},
+		{key: "cover/../../secret", err: exception.ErrInvalidFileKey},
+		{key: "cover/./a.jpg", err: exception.ErrInvalidFileKey},
+		{key: "cover//a.jpg", err: exception.ErrInvalidFileKey},
+		{key: "cover/full/", err: exception.ErrInvalidFileKey},
+	}
+
+	for _, tt := range tests {
+		got, err := cleanKey(tt.key)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("cleanKey(%q) = %q, %v, want %q, %v", tt.key, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestLocalRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "storage")
+	fs := NewLocalService(root, "/storage")
+	ctx := context.Background()
+
+	err := fs.Put(ctx, "../escaped.txt", strings.NewReader("data"), 4, "text/plain")
+	if !errors.Is(err, exception.ErrInvalidFileKey) {
+		t.Errorf("put error = %v, want %v", err, exception.ErrInvalidFileKey)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the storage root")
+	}
+
+	_, err = fs.Open(ctx, "cover/../../escaped.txt")
+	if !errors.Is(err, exception.ErrInvalidFileKey) {
+		t.Errorf("open error = %v, want %v", err, exception.ErrInvalidFileKey)
+	}
+
+	err = fs.Delete(ctx, "../storage")
+	if !errors.Is(err, exception.ErrInvalidFileKey) {
+		t.Errorf("delete error = %v, want %v", err, exception.ErrInvalidFileKey)
+	}
+}
+
+func TestLocalPutOpen(t *testing.T) {
+	fs := NewLocalService(t.TempDir(), "/storage/")
+	ctx := context.Background()
+
+	err := fs.Put(ctx, "cover/full/a.jpg", strings.NewReader("jpeg data"), 9, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fs.Get(ctx, "cover/full/a.jpg")
+	if err != nil || string(b) != "jpeg data" {
+		t.Errorf("get = %q, %v, want %q", b, err, "jpeg data")
+	}
+
+	_, err = fs.Open(ctx, "cover/full/missing.jpg")
+	if !errors.Is(err, exception.ErrFileNotFound) {
+		t.Errorf("open error = %v, want %v", err, exception.ErrFileNotFound)
+	}
+
+	url, err := fs.URL(ctx, "cover/full/a.jpg")
+	if err != nil || url != "/storage/cover/full/a.jpg" {
+		t.Errorf("url = %q, %v, want /storage/cover/full/a.jpg", url, err)
+	}
+}
@@ -0,0 +1,321 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/file"
+)
+
+var (
+	UNSIGNED_PAYLOAD = "UNSIGNED-PAYLOAD"
+	SIGV4_ALGORITHM  = "AWS4-HMAC-SHA256"
+)
+
+// Options of S3 compatible storage.
+// PathStyle must be true for MinIO and most self hosted storage.
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+	PathStyle bool
+	URLExpiry time.Duration
+}
+
+// Storage driver that saves files to S3 compatible object storage.
+// Requests are signed with AWS Signature Version 4.
+type s3FileService struct {
+	options S3Options
+	client  *http.Client
+}
+
+func (fs *s3FileService) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	// the request must have content length, buffer the body if unknown
+	if size < 0 {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		r = bytes.NewReader(b)
+		size = int64(len(b))
+	}
+
+	req, err := fs.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := fs.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return nil
+}
+
+func (fs *s3FileService) Get(ctx context.Context, key string) ([]byte, error) {
+	rc, err := fs.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func (fs *s3FileService) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := fs.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := fs.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+func (fs *s3FileService) Delete(ctx context.Context, key string) error {
+	req, err := fs.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := fs.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return nil
+}
+
+// Get URL of the file. If public URL is configured the bucket is
+// assumed public readable, otherwise returning presigned URL.
+func (fs *s3FileService) URL(ctx context.Context, key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if fs.options.PublicURL != "" {
+		return strings.TrimSuffix(fs.options.PublicURL, "/") + "/" + encodePath(cleaned), nil
+	}
+
+	u, err := fs.objectURL(cleaned)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := fs.scope(now)
+
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", SIGV4_ALGORITHM)
+	q.Set("X-Amz-Credential", fs.options.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.Itoa(int(fs.options.URLExpiry.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		UNSIGNED_PAYLOAD,
+	}, "\n")
+
+	q.Set("X-Amz-Signature", fs.signature(now, amzDate, scope, canonicalRequest))
+	u.RawQuery = canonicalQuery(q)
+
+	return u.String(), nil
+}
+
+// Create signed request for the object key.
+func (fs *s3FileService) newRequest(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := fs.objectURL(cleaned)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := fs.scope(now)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", UNSIGNED_PAYLOAD)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + u.Host + "\n" +
+		"x-amz-content-sha256:" + UNSIGNED_PAYLOAD + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery(u.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		UNSIGNED_PAYLOAD,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		SIGV4_ALGORITHM, fs.options.AccessKey, scope, signedHeaders, fs.signature(now, amzDate, scope, canonicalRequest)))
+
+	return req, nil
+}
+
+// Send the request, non 2xx response is returned as error.
+func (fs *s3FileService) do(req *http.Request) (*http.Response, error) {
+	res, err := fs.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, exception.ErrFileNotFound
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s %s", exception.ErrStorageRequestFailure, res.Status, body)
+	}
+
+	return res, nil
+}
+
+// Get the object URL, using path style or virtual hosted style.
+func (fs *s3FileService) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(fs.options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.options.PathStyle {
+		u.Path = "/" + fs.options.Bucket + "/" + key
+	} else {
+		u.Host = fs.options.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+
+	u.RawPath = strings.TrimSuffix(u.Path, key) + encodePath(key)
+
+	return u, nil
+}
+
+// Get credential scope of the signature.
+func (fs *s3FileService) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + fs.options.Region + "/s3/aws4_request"
+}
+
+// Calculate the signature of canonical request.
+func (fs *s3FileService) signature(t time.Time, amzDate string, scope string, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		SIGV4_ALGORITHM,
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+fs.options.SecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, fs.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// Encode the query sorted by key as required by signature.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		values := q[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, encode(k, true)+"="+encode(v, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// Encode the path, every segment is encoded but the slash is kept.
+func encodePath(p string) string {
+	return encode(p, false)
+}
+
+// URI encode as specified by AWS, only unreserved characters are kept.
+func encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func NewS3Service(options S3Options) FileService {
+	return &s3FileService{
+		options: options,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
@@ -0,0 +1,275 @@
+package file
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/file"
+)
+
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/([^,]+), SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+// Fake S3 compatible storage keeping the objects in memory.
+// Every request must be signed with the expected credentials.
+type fakeS3 struct {
+	t       *testing.T
+	options S3Options
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := f.verify(r)
+	if err != nil {
+		f.t.Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Verify the signature of the request header, calculated independently
+// from the signing code of the driver.
+func (f *fakeS3) verify(r *http.Request) error {
+	m := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return errors.New("malformed authorization header")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if m[1] != f.options.AccessKey || m[2] != amzDate[:8]+"/"+f.options.Region+"/s3/aws4_request" {
+		return errors.New("unexpected credential " + m[1] + "/" + m[2])
+	}
+
+	var canonicalHeaders string
+	for _, h := range strings.Split(m[3], ";") {
+		v := r.Header.Get(h)
+		if h == "host" {
+			v = r.Host
+		}
+
+		canonicalHeaders += h + ":" + v + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders, m[3], r.Header.Get("X-Amz-Content-Sha256")}, "\n")
+	if sign(f.options, amzDate, canonicalRequest) != m[4] {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+func sign(o S3Options, amzDate string, canonicalRequest string) string {
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	scope := amzDate[:8] + "/" + o.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := mac([]byte("AWS4"+o.SecretKey), amzDate[:8])
+	key = mac(key, o.Region)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+
+	return hex.EncodeToString(mac(key, stringToSign))
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, FileService) {
+	options := S3Options{
+		Region:    "us-east-1",
+		Bucket:    "storial",
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		PathStyle: true,
+		URLExpiry: 15 * time.Minute,
+	}
+
+	fake := &fakeS3{t: t, options: options, objects: make(map[string][]byte), types: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	options.Endpoint = server.URL
+
+	return fake, NewS3Service(options)
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	fake, fs := newFakeS3(t)
+	ctx := context.Background()
+	key := "cover/full/my cover+1.jpg"
+
+	err := fs.Put(ctx, key, strings.NewReader("jpeg data"), -1, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fake.types["/storial/"+key]; got != "image/jpeg" {
+		t.Errorf("content type = %q, want image/jpeg", got)
+	}
+
+	b, err := fs.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "jpeg data" {
+		t.Errorf("get = %q, want %q", b, "jpeg data")
+	}
+
+	err = fs.Delete(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fs.Get(ctx, key)
+	if !errors.Is(err, exception.ErrFileNotFound) {
+		t.Errorf("get after delete error = %v, want %v", err, exception.ErrFileNotFound)
+	}
+}
+
+func TestS3NotFound(t *testing.T) {
+	_, fs := newFakeS3(t)
+
+	_, err := fs.Open(context.Background(), "cover/full/missing.jpg")
+	if !errors.Is(err, exception.ErrFileNotFound) {
+		t.Errorf("open error = %v, want %v", err, exception.ErrFileNotFound)
+	}
+}
+
+func TestS3RequestFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "SlowDown", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fs := NewS3Service(S3Options{Endpoint: server.URL, Region: "us-east-1", Bucket: "storial", PathStyle: true})
+
+	_, err := fs.Open(context.Background(), "cover/full/a.jpg")
+	if !errors.Is(err, exception.ErrStorageRequestFailure) {
+		t.Errorf("open error = %v, want %v", err, exception.ErrStorageRequestFailure)
+	}
+}
+
+func TestS3InvalidKey(t *testing.T) {
+	_, fs := newFakeS3(t)
+
+	for _, key := range []string{"", "../secret", "cover/../../secret"} {
+		_, err := fs.Open(context.Background(), key)
+		if !errors.Is(err, exception.ErrInvalidFileKey) {
+			t.Errorf("open %q error = %v, want %v", key, err, exception.ErrInvalidFileKey)
+		}
+	}
+}
+
+func TestS3PresignedURL(t *testing.T) {
+	fake, fs := newFakeS3(t)
+
+	raw, err := fs.URL(context.Background(), "cover/full/my cover.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := u.EscapedPath(); got != "/storial/cover/full/my%20cover.jpg" {
+		t.Errorf("path = %q, want /storial/cover/full/my%%20cover.jpg", got)
+	}
+
+	q := u.Query()
+	amzDate := q.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil {
+		t.Fatalf("X-Amz-Date = %q: %v", amzDate, err)
+	}
+
+	want := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    "AKIDEXAMPLE/" + amzDate[:8] + "/us-east-1/s3/aws4_request",
+		"X-Amz-Expires":       "900",
+		"X-Amz-SignedHeaders": "host",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	// the query is sorted and the signature is calculated without itself
+	signature := q.Get("X-Amz-Signature")
+	unsigned := strings.Replace(u.RawQuery, "&X-Amz-Signature="+signature, "", 1)
+	canonicalRequest := strings.Join([]string{http.MethodGet, u.EscapedPath(), unsigned, "host:" + u.Host + "\n", "host", UNSIGNED_PAYLOAD}, "\n")
+	if got := sign(fake.options, amzDate, canonicalRequest); got != signature {
+		t.Errorf("X-Amz-Signature = %q, want %q", signature, got)
+	}
+}
+
+func TestS3PublicURL(t *testing.T) {
+	fs := NewS3Service(S3Options{Endpoint: "http://localhost:9000", Bucket: "storial", PublicURL: "https://cdn.example.com/"})
+
+	got, err := fs.URL(context.Background(), "cover/full/my cover.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "https://cdn.example.com/cover/full/my%20cover.jpg"; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestS3VirtualHostedURL(t *testing.T) {
+	fs := NewS3Service(S3Options{Endpoint: "https://s3.us-east-1.amazonaws.com", Region: "us-east-1", Bucket: "storial", AccessKey: "AKIDEXAMPLE", URLExpiry: time.Minute})
+
+	raw, err := fs.URL(context.Background(), "cover/full/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Host != "storial.s3.us-east-1.amazonaws.com" || u.Path != "/cover/full/a.jpg" {
+		t.Errorf("url = %q, want bucket on the host", raw)
+	}
+}
@@ -0,0 +1,250 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/library"
+	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/library"
+	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	chapterexception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	storyexception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+type libraryService struct {
+	libraryRepository library.LibraryRepository
+	storyRepository   story.StoryRepository
+	chapterRepository chapter.ChapterRepository
+	db                *sql.DB
+}
+
+// Get bookmarked stories of the user with the reading progress.
+func (ls *libraryService) GetLibrary(ctx context.Context, userID uint64, r pagination.Request) (*[]model.LibraryResponse, *pagination.Meta, error) {
+	tx, err := ls.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bookmarks, err := ls.libraryRepository.FindAllBookmarkByUserID(ctx, tx, userID, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := ls.libraryRepository.CountBookmarkByUserID(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *bookmarks)
+
+	var cursors []pagination.Cursor
+	var libraryResponse []model.LibraryResponse
+	for _, b := range rows {
+		cursors = append(cursors, pagination.Cursor{Value: b.CreatedAt, ID: b.StoryID})
+
+		response := model.LibraryResponse{
+			Story: model.StoryResponseByLibrary{
+				Id: b.Story.Id,
+				User: usermodel.UserResponseByChapter{
+					Id:       b.Story.User.Id,
+					Name:     b.Story.User.Name,
+					Username: b.Story.User.Username,
+				},
+				Title:   b.Story.Title,
+				Slug:    b.Story.Slug,
+				IsAdult: b.Story.IsAdult,
+			},
+			BookmarkedAt: time.UnixToTime(b.CreatedAt),
+		}
+
+		if b.Progress != nil {
+			response.Progress = progressResponse(*b.Progress)
+		}
+
+		libraryResponse = append(libraryResponse, response)
+	}
+
+	return &libraryResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+func (ls *libraryService) Bookmark(ctx context.Context, viewer entity.Viewer, storyID string) (*model.BookmarkResponse, error) {
+	tx, err := ls.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	story, err := ls.findVisibleStory(ctx, tx, viewer, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ls.libraryRepository.SaveBookmark(ctx, tx, entity.Bookmark{
+		UserID:    viewer.UserID,
+		StoryID:   story.Id,
+		CreatedAt: time.CurrentTimeToUnixTimestamp(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BookmarkResponse{Bookmarked: true}, nil
+}
+
+func (ls *libraryService) Unbookmark(ctx context.Context, userID uint64, storyID string) (*model.BookmarkResponse, error) {
+	tx, err := ls.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	story, err := ls.findStory(ctx, tx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ls.libraryRepository.DeleteBookmark(ctx, tx, userID, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BookmarkResponse{Bookmarked: false}, nil
+}
+
+// Get reading progress of the user on the story.
+func (ls *libraryService) GetProgress(ctx context.Context, viewer entity.Viewer, storyID string) (*model.ProgressResponse, error) {
+	tx, err := ls.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	story, err := ls.findVisibleStory(ctx, tx, viewer, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := ls.libraryRepository.FindProgress(ctx, tx, viewer.UserID, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return progressResponse(*progress), nil
+}
+
+// Report the chapter and the scroll position explicitly, usually sent
+// by the client periodically while the user is reading.
+func (ls *libraryService) ReportProgress(ctx context.Context, viewer entity.Viewer, r model.UpdateProgressRequest) (*model.ProgressResponse, error) {
+	tx, err := ls.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := ls.findVisibleStory(ctx, tx, viewer, r.StoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	chapterID, _ := strconv.ParseUint(r.ChapterID, 10, 64)
+	chapter, err := ls.chapterRepository.FindByID(ctx, tx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chapter.StoryID != story.Id || !viewer.CanSeeChapter(*chapter, *story) {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	var scrollPosition uint64
+	if r.ScrollPosition != "" {
+		scrollPosition, _ = strconv.ParseUint(r.ScrollPosition, 10, 64)
+	}
+
+	err = ls.libraryRepository.SaveProgress(ctx, tx, entity.ReadingProgress{
+		UserID:         r.UserID,
+		StoryID:        story.Id,
+		ChapterID:      chapter.Id,
+		ScrollPosition: scrollPosition,
+		UpdatedAt:      time.CurrentTimeToUnixTimestamp(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := ls.libraryRepository.FindProgress(ctx, tx, r.UserID, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return progressResponse(*progress), nil
+}
+
+// Find the story by ID from the path.
+func (ls *libraryService) findStory(ctx context.Context, tx *sql.Tx, storyID string) (*entity.Story, error) {
+	id, err := strconv.ParseUint(storyID, 10, 64)
+	if err != nil {
+		return nil, storyexception.ErrStoryNotFound
+	}
+
+	return ls.storyRepository.FindByID(ctx, tx, id)
+}
+
+// Find the story by ID from the path and make sure the viewer can read it.
+func (ls *libraryService) findVisibleStory(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, storyID string) (*entity.Story, error) {
+	story, err := ls.findStory(ctx, tx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanSeeStory(*story) {
+		return nil, storyexception.ErrStoryNotFound
+	}
+
+	if !viewer.CanReadAdultStory(*story) {
+		return nil, storyexception.ErrAdultContentRestricted
+	}
+
+	return story, nil
+}
+
+func progressResponse(p entity.ReadingProgress) *model.ProgressResponse {
+	return &model.ProgressResponse{
+		StoryID: p.StoryID,
+		Chapter: model.ChapterResponseByProgress{
+			Id:    p.Chapter.Id,
+			Title: p.Chapter.Title,
+			Slug:  p.Chapter.Slug,
+		},
+		ScrollPosition: p.ScrollPosition,
+		Percentage:     p.Percentage(),
+		UpdatedAt:      time.UnixToTime(p.UpdatedAt),
+	}
+}
+
+func NewService(lr library.LibraryRepository, sr story.StoryRepository, cr chapter.ChapterRepository, db *sql.DB) LibraryService {
+	return &libraryService{
+		libraryRepository: lr,
+		storyRepository:   sr,
+		chapterRepository: cr,
+		db:                db,
+	}
+}
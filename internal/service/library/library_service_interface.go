@@ -0,0 +1,17 @@
+package library
+
+import (
+	"context"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/library"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type LibraryService interface {
+	GetLibrary(ctx context.Context, userID uint64, r pagination.Request) (*[]model.LibraryResponse, *pagination.Meta, error)
+	Bookmark(ctx context.Context, viewer entity.Viewer, storyID string) (*model.BookmarkResponse, error)
+	Unbookmark(ctx context.Context, userID uint64, storyID string) (*model.BookmarkResponse, error)
+	GetProgress(ctx context.Context, viewer entity.Viewer, storyID string) (*model.ProgressResponse, error)
+	ReportProgress(ctx context.Context, viewer entity.Viewer, r model.UpdateProgressRequest) (*model.ProgressResponse, error)
+}
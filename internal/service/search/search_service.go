@@ -0,0 +1,92 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrizkimaulidan/storial/internal/database"
+	model "github.com/mrizkimaulidan/storial/internal/model/search"
+	storymodel "github.com/mrizkimaulidan/storial/internal/model/story"
+	usermodel "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/internal/repository/search"
+	"github.com/mrizkimaulidan/storial/pkg/highlight"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+var (
+	SNIPPET_RADIUS = 80
+)
+
+type searchService struct {
+	searchRepository search.SearchRepository
+	db               *sql.DB
+}
+
+func (ss *searchService) Search(ctx context.Context, r model.SearchRequest, p pagination.Request) (*[]model.SearchResponse, *pagination.Meta, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, err := p.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	terms := highlight.Terms(r.Query)
+	results, err := ss.searchRepository.Search(ctx, tx, r.Query, terms, SNIPPET_RADIUS, r.IncludeAdult, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := ss.searchRepository.CountSearch(ctx, tx, r.Query, r.IncludeAdult)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *results)
+
+	var searchResponse []model.SearchResponse
+	for _, hit := range rows {
+		sr := model.SearchResponse{
+			Type:  hit.Type,
+			Score: hit.Score,
+			Story: storymodel.StoryResponseByChapter{
+				Id:    hit.Story.Id,
+				Title: hit.Story.Title,
+				Slug:  hit.Story.Slug,
+			},
+			User: usermodel.UserResponseByFilter{
+				Id:       hit.User.Id,
+				Name:     hit.User.Name,
+				Username: hit.User.Username,
+			},
+			Snippet: highlight.Snippet(hit.Text, terms, SNIPPET_RADIUS),
+		}
+
+		if hit.Type == search.TYPE_CHAPTER {
+			sr.Chapter = &model.SearchChapterResponse{
+				Id:    hit.Chapter.Id,
+				Title: hit.Chapter.Title,
+				Slug:  hit.Chapter.Slug,
+			}
+		}
+
+		searchResponse = append(searchResponse, sr)
+	}
+
+	return &searchResponse, pagination.NewMeta(*page, *total, hasMore, nil), nil
+}
+
+func NewService(sr search.SearchRepository, db *sql.DB) SearchService {
+	return &searchService{
+		searchRepository: sr,
+		db:               db,
+	}
+}
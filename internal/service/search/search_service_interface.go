@@ -0,0 +1,12 @@
+package search
+
+import (
+	"context"
+
+	model "github.com/mrizkimaulidan/storial/internal/model/search"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type SearchService interface {
+	Search(ctx context.Context, r model.SearchRequest, p pagination.Request) (*[]model.SearchResponse, *pagination.Meta, error)
+}
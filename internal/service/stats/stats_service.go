@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	gotime "time"
+
+	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/stats"
+	"github.com/mrizkimaulidan/storial/internal/repository/stats"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+type statsService struct {
+	statsRepository stats.StatsRepository
+	db              *sql.DB
+}
+
+// Get statistics of every story and chapter owned by the writer.
+// Likes over time covers the last days including today, counted in UTC.
+func (ss *statsService) GetWriterStats(ctx context.Context, r model.StatsRequest) (*model.WriterStatsResponse, error) {
+	if r.Days == "" {
+		r.Days = model.DEFAULT_DAYS
+	}
+
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := strconv.ParseUint(r.Days, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	today := time.UnixToTime(time.CurrentTimeToUnixTimestamp()).UTC().Truncate(24 * gotime.Hour)
+	since := uint64(today.AddDate(0, 0, -int(days-1)).UnixMilli())
+
+	storyStats, err := ss.statsRepository.FindStoryStatsByUserID(ctx, tx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	chapterStats, err := ss.statsRepository.FindChapterStatsByUserID(ctx, tx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	storyLikes, err := ss.statsRepository.FindDailyStoryLikesByUserID(ctx, tx, r.UserID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	chapterLikes, err := ss.statsRepository.FindDailyChapterLikesByUserID(ctx, tx, r.UserID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	likesByStory := make(map[uint64][]model.DailyLikesResponse)
+	for _, l := range *storyLikes {
+		likesByStory[l.StoryID] = append(likesByStory[l.StoryID], dailyLikesResponse(l))
+	}
+
+	likesByChapter := make(map[uint64][]model.DailyLikesResponse)
+	for _, l := range *chapterLikes {
+		likesByChapter[l.ChapterID] = append(likesByChapter[l.ChapterID], dailyLikesResponse(l))
+	}
+
+	chaptersByStory := make(map[uint64][]model.ChapterStatsResponse)
+	for _, s := range *chapterStats {
+		chaptersByStory[s.Chapter.StoryID] = append(chaptersByStory[s.Chapter.StoryID], model.ChapterStatsResponse{
+			Id:                s.Chapter.Id,
+			Title:             s.Chapter.Title,
+			Slug:              s.Chapter.Slug,
+			IsPublished:       s.Chapter.IsPublished,
+			Position:          s.Chapter.Position,
+			Views:             s.Views,
+			UniqueReaders:     s.UniqueReaders,
+			Likes:             s.Likes,
+			LikesOverTime:     nonNilDailyLikes(likesByChapter[s.Chapter.Id]),
+			Comments:          s.Comments,
+			AverageCompletion: s.AverageCompletion,
+		})
+	}
+
+	storiesResponse := []model.StoryStatsResponse{}
+	for _, s := range *storyStats {
+		chapters := chaptersByStory[s.Story.Id]
+		if chapters == nil {
+			chapters = []model.ChapterStatsResponse{}
+		}
+
+		storiesResponse = append(storiesResponse, model.StoryStatsResponse{
+			Id:                s.Story.Id,
+			Title:             s.Story.Title,
+			Slug:              s.Story.Slug,
+			IsPublished:       s.Story.IsPublished,
+			Views:             s.Views,
+			UniqueReaders:     s.UniqueReaders,
+			Likes:             s.Likes,
+			LikesOverTime:     nonNilDailyLikes(likesByStory[s.Story.Id]),
+			Comments:          s.Comments,
+			AverageCompletion: s.AverageCompletion,
+			Chapters:          chapters,
+		})
+	}
+
+	return &model.WriterStatsResponse{
+		Days:    days,
+		Stories: storiesResponse,
+	}, nil
+}
+
+func dailyLikesResponse(l entity.DailyLikes) model.DailyLikesResponse {
+	return model.DailyLikesResponse{
+		Date:  time.UnixToTime(l.Day).UTC().Format(model.DAY_LAYOUT),
+		Likes: l.Likes,
+	}
+}
+
+// Empty likes over time is encoded as an empty array instead of null.
+func nonNilDailyLikes(likes []model.DailyLikesResponse) []model.DailyLikesResponse {
+	if likes == nil {
+		return []model.DailyLikesResponse{}
+	}
+
+	return likes
+}
+
+func NewService(sr stats.StatsRepository, db *sql.DB) StatsService {
+	return &statsService{
+		statsRepository: sr,
+		db:              db,
+	}
+}
@@ -0,0 +1,11 @@
+package stats
+
+import (
+	"context"
+
+	model "github.com/mrizkimaulidan/storial/internal/model/stats"
+)
+
+type StatsService interface {
+	GetWriterStats(ctx context.Context, r model.StatsRequest) (*model.WriterStatsResponse, error)
+}
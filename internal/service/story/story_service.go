@@ -1,9 +1,16 @@
 package story
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"image"
+	"io"
+	"log"
+	"mime/multipart"
+	"path"
 	"strconv"
 
 	"github.com/mrizkimaulidan/storial/internal/database"
@@ -15,14 +22,38 @@ import (
 	"github.com/mrizkimaulidan/storial/internal/repository/story"
 	chapterservice "github.com/mrizkimaulidan/storial/internal/service/chapter"
 	"github.com/mrizkimaulidan/storial/internal/service/file"
+	"github.com/mrizkimaulidan/storial/pkg/epub"
+	chapterexception "github.com/mrizkimaulidan/storial/pkg/exception/chapter"
+	fileexception "github.com/mrizkimaulidan/storial/pkg/exception/file"
 	exception "github.com/mrizkimaulidan/storial/pkg/exception/story"
+	"github.com/mrizkimaulidan/storial/pkg/imaging"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	slugpkg "github.com/mrizkimaulidan/storial/pkg/slug"
+	"github.com/mrizkimaulidan/storial/pkg/snowflake"
 	"github.com/mrizkimaulidan/storial/pkg/time"
 )
 
 var (
-	COVER_PATH = "public/cover"
+	COVER_PATH           = "cover"
+	COVER_MAX_SIZE int64 = 5 << 20 // 5 MB
+
+	// Every uploaded cover is resized into these variants,
+	// the full variant is the default one.
+	COVER_VARIANTS = []coverVariant{
+		{Name: "thumbnail", Width: 160, Height: 240},
+		{Name: "card", Width: 400, Height: 600},
+		{Name: "full", Width: 1200, Height: 1800},
+	}
+	COVER_DEFAULT_VARIANT = "full"
 )
 
+// Cover variant, the image is resized to fit inside the width and height.
+type coverVariant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
 type storyService struct {
 	storyRepository   story.StoryRepository
 	fileService       file.FileService
@@ -31,10 +62,15 @@ type storyService struct {
 	db                *sql.DB
 }
 
-func (ss *storyService) ProcessStoryResponseFilter(ctx context.Context, tx *sql.Tx, stories []entity.Story) (*[]model.StoryResponseByFilter, error) {
+func (ss *storyService) ProcessStoryResponseFilter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, stories []entity.Story) (*[]model.StoryResponseByFilter, error) {
 	var storiesResponse []model.StoryResponseByFilter
 	for _, s := range stories {
-		chapterCounts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, s.Slug)
+		chapterCounts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, s.Slug, viewer)
+		if err != nil {
+			return nil, err
+		}
+
+		cover, err := ss.coverURLs(ctx, s)
 		if err != nil {
 			return nil, err
 		}
@@ -51,7 +87,7 @@ func (ss *storyService) ProcessStoryResponseFilter(ctx context.Context, tx *sql.
 			Slug:          s.Slug,
 			ChapterCounts: *chapterCounts,
 			IsAdult:       s.IsAdult,
-			Cover:         s.CoverPath(),
+			Cover:         cover,
 			CreatedAt:     time.UnixToTime(s.CreatedAt),
 		}
 
@@ -61,10 +97,10 @@ func (ss *storyService) ProcessStoryResponseFilter(ctx context.Context, tx *sql.
 	return &storiesResponse, nil
 }
 
-func (ss *storyService) ProcessStoryResponseFilterByCategorySlug(ctx context.Context, tx *sql.Tx, stories []entity.Story) (*[]model.StoryResponseByCategorySlug, error) {
+func (ss *storyService) ProcessStoryResponseFilterByCategorySlug(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, stories []entity.Story) (*[]model.StoryResponseByCategorySlug, error) {
 	var storiesResponse []model.StoryResponseByCategorySlug
 	for _, s := range stories {
-		chapterCounts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, s.Slug)
+		chapterCounts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, s.Slug, viewer)
 		if err != nil {
 			return nil, err
 		}
@@ -90,87 +126,137 @@ func (ss *storyService) ProcessStoryResponseFilterByCategorySlug(ctx context.Con
 	return &storiesResponse, nil
 }
 
-func (ss *storyService) FilterStoryByCategorySlug(ctx context.Context, categorySlug string, filterType string) (*[]model.StoryResponseByCategorySlug, error) {
+func (ss *storyService) FilterStoryByCategorySlug(ctx context.Context, viewer entity.Viewer, categorySlug string, filterType string, r pagination.Request) (*[]model.StoryResponseByCategorySlug, *pagination.Meta, error) {
 	tx, err := ss.db.Begin()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	switch filterType {
 	case "time":
-		stories, err := ss.storyRepository.FilterLatestBasedOnCategorySlug(ctx, tx, categorySlug)
+		stories, err := ss.storyRepository.FilterLatestBasedOnCategorySlug(ctx, tx, categorySlug, viewer, *page)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, *stories)
+		total, err := ss.storyRepository.CountStoryByCategorySlug(ctx, tx, categorySlug, viewer)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return storiesResponse, nil
+		rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.CreatedAt })
+
+		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, viewer, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storiesResponse, meta, nil
 	case "modified":
-		stories, err := ss.storyRepository.FilterLatestModifiedChapterByCategorySlug(ctx, tx, categorySlug)
+		stories, err := ss.storyRepository.FilterLatestModifiedChapterByCategorySlug(ctx, tx, categorySlug, viewer, *page)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, *stories)
+		total, err := ss.storyRepository.CountStoryWithChapterByCategorySlug(ctx, tx, categorySlug, viewer)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.LatestChapterUpdatedAt })
+
+		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, viewer, rows)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		return storiesResponse, nil
+		return storiesResponse, meta, nil
 	default:
-		stories, err := ss.storyRepository.FindByCategorySlug(ctx, tx, categorySlug)
+		stories, err := ss.storyRepository.FindByCategorySlug(ctx, tx, categorySlug, viewer, *page)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, *stories)
+		total, err := ss.storyRepository.CountStoryByCategorySlug(ctx, tx, categorySlug, viewer)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return storiesResponse, nil
+		rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.Id })
+
+		storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, viewer, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storiesResponse, meta, nil
 	}
 }
 
-func (ss *storyService) GetStoryByCategorySlug(ctx context.Context, categorySlug string) (*[]model.StoryResponseByCategorySlug, error) {
+func (ss *storyService) GetStoryByCategorySlug(ctx context.Context, viewer entity.Viewer, categorySlug string, r pagination.Request) (*[]model.StoryResponseByCategorySlug, *pagination.Meta, error) {
 	tx, err := ss.db.Begin()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
-	stories, err := ss.storyRepository.FindByCategorySlug(ctx, tx, categorySlug)
+	page, err := r.Parse()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, *stories)
+	stories, err := ss.storyRepository.FindByCategorySlug(ctx, tx, categorySlug, viewer, *page)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	total, err := ss.storyRepository.CountStoryByCategorySlug(ctx, tx, categorySlug, viewer)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return storiesResponse, nil
+	rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.Id })
+
+	storiesResponse, err := ss.ProcessStoryResponseFilterByCategorySlug(ctx, tx, viewer, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return storiesResponse, meta, nil
 }
 
-func (ss *storyService) GetAllStory(ctx context.Context, userID uint64) (*[]model.StoryResponse, error) {
+func (ss *storyService) GetAllStory(ctx context.Context, userID uint64, r pagination.Request) (*[]model.StoryResponse, *pagination.Meta, error) {
 	tx, err := ss.db.Begin()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
-	stories, err := ss.storyRepository.FindAllByUserID(ctx, tx, userID)
+	page, err := r.Parse()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	stories, err := ss.storyRepository.FindAllByUserID(ctx, tx, userID, *page)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	total, err := ss.storyRepository.CountStoryByUserID(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.CreatedAt })
+
 	var storiesResponse []model.StoryResponse
-	for _, s := range *stories {
+	for _, s := range rows {
 		storyResponse := model.StoryResponse{
 			Id:     s.Id,
 			UserID: s.UserID,
@@ -182,6 +268,7 @@ func (ss *storyService) GetAllStory(ctx context.Context, userID uint64) (*[]mode
 			Title:       s.Title,
 			Slug:        s.Slug,
 			IsPublished: s.IsPublished,
+			PublishAt:   time.NullUnixToTime(s.PublishAt),
 			CreatedAt:   time.UnixToTime(s.CreatedAt),
 			UpdatedAt:   time.UnixToTime(s.UpdatedAt),
 		}
@@ -189,7 +276,7 @@ func (ss *storyService) GetAllStory(ctx context.Context, userID uint64) (*[]mode
 		storiesResponse = append(storiesResponse, storyResponse)
 	}
 
-	return &storiesResponse, nil
+	return &storiesResponse, meta, nil
 }
 
 func (ss *storyService) RemoveStory(ctx context.Context, r model.DeleteStoryRequest) (*model.DeletetedStoryResponse, error) {
@@ -214,22 +301,36 @@ func (ss *storyService) RemoveStory(ctx context.Context, r model.DeleteStoryRequ
 		return nil, err
 	}
 
-	ss.fileService.RemoveFile(story.Cover)
+	ss.removeCover(ctx, story.Cover)
 
 	return &model.DeletetedStoryResponse{
 		Status: true,
 	}, nil
 }
 
-func (ss *storyService) LoadStoryImageCover(ctx context.Context, filename string) ([]byte, error) {
-	ss.fileService.SetFilename(filename)
-	fullPath := ss.fileService.GetFullPath()
+func (ss *storyService) LoadStoryImageCover(ctx context.Context, filename string, variant string) ([]byte, error) {
+	// the filename cannot contain any directory
+	if filename == "" || path.Base(filename) != filename {
+		return nil, exception.ErrCoverImageNotFound
+	}
 
-	bytes, err := ss.fileService.Get(fullPath)
-	if err != nil {
+	if variant == "" {
+		variant = COVER_DEFAULT_VARIANT
+	}
+
+	if !isCoverVariant(variant) {
 		return nil, exception.ErrCoverImageNotFound
 	}
 
+	bytes, err := ss.fileService.Get(ctx, path.Join(COVER_PATH, variant, filename))
+	if err != nil {
+		if errors.Is(err, fileexception.ErrFileNotFound) || errors.Is(err, fileexception.ErrInvalidFileKey) {
+			return nil, exception.ErrCoverImageNotFound
+		}
+
+		return nil, err
+	}
+
 	return bytes, nil
 }
 
@@ -238,8 +339,25 @@ func (ss *storyService) EditStory(ctx context.Context, r model.UpdateStoryReques
 	if err != nil {
 		return nil, err
 	}
+
+	// the uploaded cover is removed when the transaction is rolled back,
+	// otherwise the replaced cover is removed after the transaction is committed
+	var uploaded, replaced string
+	defer func() {
+		if err := recover(); err != nil {
+			ss.removeCover(ctx, uploaded)
+			panic(err)
+		}
+
+		ss.removeCover(ctx, replaced)
+	}()
 	defer database.CommitOrRollback(tx)
 
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	categoryID, err := strconv.Atoi(r.CategoryID)
 	if err != nil {
 		return nil, err
@@ -255,38 +373,67 @@ func (ss *storyService) EditStory(ctx context.Context, r model.UpdateStoryReques
 		return nil, err
 	}
 
+	publishAt, err := schedulePublish(r.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
 	story, err := ss.storyRepository.FindBySlugAndUserID(ctx, tx, r.Slug, r.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	var s entity.Story
-	s = entity.Story{
+	s := entity.Story{
 		Id:          story.Id,
 		UserID:      r.UserID,
 		CategoryID:  uint64(categoryID),
 		Title:       r.Title,
-		Slug:        fmt.Sprintf("%s-%d", s.ToSlug(r.Title), story.Id),
 		Description: r.Description,
 		IsAdult:     isAdult,
-		IsPublished: isPublished,
+		IsPublished: isPublished && !publishAt.Valid,
 		UpdatedAt:   time.CurrentTimeToUnixTimestamp(),
+		PublishAt:   publishAt,
 	}
 
 	// upload file if file exists on request struct
 	if r.Cover != nil {
-		filename, err := ss.fileService.Upload(r.Cover, r.CoverFileheader)
+		filename, err := ss.uploadCover(ctx, r.Cover, r.CoverFileheader)
 		if err != nil {
 			return nil, err
 		}
 
 		s.Cover = filename
+		uploaded = filename
+	}
+
+	var updatedStory *entity.Story
+	err = ss.saveWithSlug(ctx, tx, r.Title, *story, func(slug string) error {
+		s.Slug = slug
+
+		updated, err := ss.storyRepository.Update(ctx, tx, r.Slug, s)
+		updatedStory = updated
+
+		return err
+	})
+	if err != nil {
+		// the story still refers to the previous cover
+		ss.removeCover(ctx, uploaded)
+		return nil, err
+	}
 
-		// remove old cover file
-		ss.fileService.RemoveFile(story.Cover)
+	if uploaded != "" {
+		replaced = story.Cover
 	}
 
-	updatedStory, err := ss.storyRepository.Update(ctx, tx, r.Slug, s)
+	// the previous slug keeps referring to the renamed story
+	if s.Slug != story.Slug {
+		err = ss.storyRepository.SaveSlugHistory(ctx, tx, story.Id, story.Slug, s.Slug, s.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cover, err := ss.coverURLs(ctx, *updatedStory)
 	if err != nil {
 		return nil, err
 	}
@@ -305,7 +452,8 @@ func (ss *storyService) EditStory(ctx context.Context, r model.UpdateStoryReques
 		Description: updatedStory.Description,
 		IsAdult:     updatedStory.IsAdult,
 		IsPublished: updatedStory.IsPublished,
-		Cover:       updatedStory.CoverPath(),
+		PublishAt:   time.NullUnixToTime(updatedStory.PublishAt),
+		Cover:       cover,
 		CreatedAt:   time.UnixToTime(updatedStory.CreatedAt),
 		UpdatedAt:   time.UnixToTime(updatedStory.UpdatedAt),
 	}, nil
@@ -339,23 +487,27 @@ func (ss *storyService) AddStory(ctx context.Context, r model.CreateStoryRequest
 		return nil, err
 	}
 
-	id := uint64(story.GenerateID())
+	publishAt, err := schedulePublish(r.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
 	story = entity.Story{
-		Id:          id,
+		Id:          story.GenerateID(),
 		UserID:      r.UserID,
 		CategoryID:  uint64(categoryID),
 		Title:       r.Title,
-		Slug:        fmt.Sprintf("%s-%d", story.ToSlug(r.Title), id),
 		Description: r.Description,
 		IsAdult:     isAdult,
-		IsPublished: isPublished,
+		IsPublished: isPublished && !publishAt.Valid,
 		CreatedAt:   time.CurrentTimeToUnixTimestamp(),
 		UpdatedAt:   time.CurrentTimeToUnixTimestamp(),
+		PublishAt:   publishAt,
 	}
 
 	// upload file if file exists on request struct
 	if r.Cover != nil {
-		filename, err := ss.fileService.Upload(r.Cover, r.CoverFileheader) // upload file to local system
+		filename, err := ss.uploadCover(ctx, r.Cover, r.CoverFileheader) // upload file to the storage
 		if err != nil {
 			return nil, err
 		}
@@ -363,7 +515,20 @@ func (ss *storyService) AddStory(ctx context.Context, r model.CreateStoryRequest
 		story.Cover = filename
 	}
 
-	createdStory, err := ss.storyRepository.Save(ctx, tx, story)
+	var createdStory *entity.Story
+	err = ss.saveWithSlug(ctx, tx, r.Title, story, func(slug string) error {
+		story.Slug = slug
+
+		created, err := ss.storyRepository.Save(ctx, tx, story)
+		createdStory = created
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cover, err := ss.coverURLs(ctx, *createdStory)
 	if err != nil {
 		return nil, err
 	}
@@ -382,13 +547,67 @@ func (ss *storyService) AddStory(ctx context.Context, r model.CreateStoryRequest
 		Description: createdStory.Description,
 		IsAdult:     createdStory.IsAdult,
 		IsPublished: createdStory.IsPublished,
-		Cover:       createdStory.CoverPath(),
+		PublishAt:   time.NullUnixToTime(createdStory.PublishAt),
+		Cover:       cover,
 		CreatedAt:   time.UnixToTime(createdStory.CreatedAt),
 		UpdatedAt:   time.UnixToTime(createdStory.UpdatedAt),
 	}, nil
 }
 
-func (ss *storyService) GetStoryBySlug(ctx context.Context, slug string) (*model.StoryResponseBySlug, error) {
+// Save the story with the slug generated from the title. The slug taken by
+// another story since it was generated is excluded, then saved again.
+func (ss *storyService) saveWithSlug(ctx context.Context, tx *sql.Tx, title string, s entity.Story, save func(slug string) error) error {
+	var excluded []string
+	for i := 0; i < slugpkg.MAX_ATTEMPTS; i++ {
+		slug, err := ss.generateSlug(ctx, tx, title, s, excluded)
+		if err != nil {
+			return err
+		}
+
+		err = save(slug)
+		if !errors.Is(err, exception.ErrSlugTaken) {
+			return err
+		}
+
+		excluded = append(excluded, slug)
+	}
+
+	return exception.ErrSlugTaken
+}
+
+// Generate slug of the title that is unique among the stories,
+// including the previous slugs of the renamed stories and the excluded slugs.
+// The current slug is kept when it is still based on the title.
+func (ss *storyService) generateSlug(ctx context.Context, tx *sql.Tx, title string, s entity.Story, excluded []string) (string, error) {
+	base := s.ToSlug(title)
+	if s.Slug != "" && slugpkg.HasBase(s.Slug, base) {
+		return s.Slug, nil
+	}
+
+	taken, err := ss.storyRepository.FindSlugsByBase(ctx, tx, base, s.Id)
+	if err != nil {
+		return "", err
+	}
+
+	return slugpkg.Unique(base, append(*taken, excluded...)), nil
+}
+
+// Find the story renamed from the slug. The visible story is
+// redirected to the current slug, otherwise the story is not found.
+func (ss *storyService) movedStory(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, slug string) error {
+	story, err := ss.storyRepository.FindByPreviousSlug(ctx, tx, slug)
+	if err != nil {
+		return err
+	}
+
+	if !viewer.CanSeeStory(*story) {
+		return exception.ErrStoryNotFound
+	}
+
+	return &exception.SlugMovedError{StorySlug: story.Slug}
+}
+
+func (ss *storyService) GetStoryBySlug(ctx context.Context, viewer entity.Viewer, slug string) (*model.StoryResponseBySlug, error) {
 	tx, err := ss.db.Begin()
 	if err != nil {
 		return nil, err
@@ -396,16 +615,29 @@ func (ss *storyService) GetStoryBySlug(ctx context.Context, slug string) (*model
 	defer database.CommitOrRollback(tx)
 
 	story, err := ss.storyRepository.FindBySlug(ctx, tx, slug)
+	if errors.Is(err, exception.ErrStoryNotFound) {
+		return nil, ss.movedStory(ctx, tx, viewer, slug)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	counts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, story.Slug)
+	// the hidden story is treated as not found so the draft existence is not leaked
+	if !viewer.CanSeeStory(*story) {
+		return nil, exception.ErrStoryNotFound
+	}
+
+	if !viewer.CanReadAdultStory(*story) {
+		return nil, exception.ErrAdultContentRestricted
+	}
+
+	counts, err := ss.chapterRepository.CountChapterByStorySlug(ctx, tx, story.Slug, viewer)
 	if err != nil {
 		return nil, err
 	}
 
-	chapters, err := ss.chapterRepository.FindAllChapterByStorySlug(ctx, tx, story.Slug)
+	chapters, err := ss.chapterRepository.FindAllChapterByStorySlug(ctx, tx, story.Slug, viewer)
 	if err != nil {
 		return nil, err
 	}
@@ -438,57 +670,353 @@ func (ss *storyService) GetStoryBySlug(ctx context.Context, slug string) (*model
 	}, nil
 }
 
-func (ss *storyService) FilterStory(ctx context.Context, filterType string) (*[]model.StoryResponseByFilter, error) {
+// Prepare the story visible to the viewer to be exported.
+// Only the published chapters are exported.
+func (ss *storyService) PrepareExport(ctx context.Context, viewer entity.Viewer, slug string) (*model.StoryExport, error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	story, err := ss.storyRepository.FindBySlug(ctx, tx, slug)
+	if errors.Is(err, exception.ErrStoryNotFound) {
+		return nil, ss.movedStory(ctx, tx, viewer, slug)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanSeeStory(*story) {
+		return nil, exception.ErrStoryNotFound
+	}
+
+	if !viewer.CanReadAdultStory(*story) {
+		return nil, exception.ErrAdultContentRestricted
+	}
+
+	chapterIDs, err := ss.chapterRepository.FindPublishedIDsByStoryID(ctx, tx, story.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var cover string
+	if story.Cover != "" {
+		cover = story.CoverPath(COVER_DEFAULT_VARIANT)
+	}
+
+	return &model.StoryExport{
+		Id:          story.Id,
+		Title:       story.Title,
+		Slug:        story.Slug,
+		Description: story.Description,
+		Author:      story.User.Name,
+		Cover:       cover,
+		ChapterIDs:  *chapterIDs,
+		UpdatedAt:   time.UnixToTime(story.UpdatedAt),
+		Filename:    story.Slug + ".epub",
+	}, nil
+}
+
+// Stream the prepared story as EPUB into w. Each chapter is loaded on
+// its own transaction while writing, so neither the book is buffered
+// nor the transaction is held until the client finished downloading.
+func (ss *storyService) WriteEpub(ctx context.Context, e model.StoryExport, w io.Writer) error {
+	book, err := epub.NewWriter(w, epub.Metadata{
+		Identifier:  fmt.Sprintf("urn:storial:story:%d", e.Id),
+		Title:       e.Title,
+		Author:      e.Author,
+		Description: e.Description,
+		Modified:    e.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.Cover != "" {
+		err = ss.writeEpubCover(ctx, book, e.Cover)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range e.ChapterIDs {
+		c, err := ss.findExportedChapter(ctx, e.Id, id)
+		if errors.Is(err, chapterexception.ErrChapterNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = book.AddChapter(c.Title, c.HTML())
+		if err != nil {
+			return err
+		}
+	}
+
+	return book.Close()
+}
+
+// Copy the cover into the book, the missing cover is skipped.
+func (ss *storyService) writeEpubCover(ctx context.Context, book *epub.Writer, key string) error {
+	rc, err := ss.fileService.Open(ctx, key)
+	if errors.Is(err, fileexception.ErrFileNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// every cover variant is encoded as JPEG
+	return book.AddCover(rc, "image/jpeg", "jpg")
+}
+
+// Find the exported chapter. The chapter that has been unpublished
+// or moved since the export is prepared is treated as not found.
+func (ss *storyService) findExportedChapter(ctx context.Context, storyID uint64, id uint64) (*entity.Chapter, error) {
 	tx, err := ss.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer database.CommitOrRollback(tx)
 
+	c, err := ss.chapterRepository.FindByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.IsPublished || c.StoryID != storyID {
+		return nil, chapterexception.ErrChapterNotFound
+	}
+
+	return c, nil
+}
+
+func (ss *storyService) FilterStory(ctx context.Context, viewer entity.Viewer, filterType string, r pagination.Request) (*[]model.StoryResponseByFilter, *pagination.Meta, error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	switch filterType {
 	case "time":
-		stories, err := ss.storyRepository.FilterLatest(ctx, tx)
+		stories, err := ss.storyRepository.FilterLatest(ctx, tx, viewer, *page)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, *stories)
+		total, err := ss.storyRepository.CountStory(ctx, tx, viewer)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return storiesResponse, nil
-	case "modified":
-		stories, err := ss.storyRepository.FilterLatestModifiedChapter(ctx, tx)
+		rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.CreatedAt })
+
+		storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, viewer, rows)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, *stories)
+		return storiesResponse, meta, nil
+	default:
+		stories, err := ss.storyRepository.FilterLatestModifiedChapter(ctx, tx, viewer, *page)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		return storiesResponse, nil
-	default:
-		stories, err := ss.storyRepository.FilterLatestModifiedChapter(ctx, tx)
+		total, err := ss.storyRepository.CountStoryWithChapter(ctx, tx, viewer)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.LatestChapterUpdatedAt })
+
+		storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, viewer, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return storiesResponse, meta, nil
+	}
+}
+
+// Get stories with recently published chapters from the followed authors.
+func (ss *storyService) GetFeed(ctx context.Context, viewer entity.Viewer, r pagination.Request) (*[]model.StoryResponseByFilter, *pagination.Meta, error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stories, err := ss.storyRepository.FilterLatestPublishedChapterByFollowerID(ctx, tx, viewer.UserID, viewer.CanSeeAdult(), *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := ss.storyRepository.CountStoryWithPublishedChapterByFollowerID(ctx, tx, viewer.UserID, viewer.CanSeeAdult())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, meta := paginateStories(*page, *stories, *total, func(s entity.Story) uint64 { return s.LatestChapterPublishedAt })
+
+	storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, viewer, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return storiesResponse, meta, nil
+}
+
+// Validate the uploaded cover, then resize and re-encode it into every variant.
+// Re-encoding the image also strips the metadata such as EXIF.
+// Returning the filename to be saved on the story.
+func (ss *storyService) uploadCover(ctx context.Context, f multipart.File, fileheader *multipart.FileHeader) (string, error) {
+	if fileheader.Size > COVER_MAX_SIZE {
+		return "", exception.ErrCoverImageTooLarge
+	}
+
+	img, _, err := imaging.Decode(f, COVER_MAX_SIZE)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrFileTooLarge), errors.Is(err, imaging.ErrDimensionTooLarge):
+			return "", exception.ErrCoverImageTooLarge
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			return "", exception.ErrCoverImageInvalid
+		}
+
+		return "", err
+	}
+
+	filename := fmt.Sprintf("%d.jpg", snowflake.Generate())
+	err = PutCoverVariants(ctx, ss.fileService, img, filename)
+	if err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
+
+// Resize and encode the image into every cover variant as JPEG, then put them
+// into the storage. If one of the variants failed, the uploaded ones are removed.
+func PutCoverVariants(ctx context.Context, fs file.FileService, img image.Image, filename string) error {
+	for i, v := range COVER_VARIANTS {
+		var buf bytes.Buffer
+		err := imaging.EncodeJPEG(&buf, imaging.Fit(img, v.Width, v.Height))
+		if err == nil {
+			err = fs.Put(ctx, path.Join(COVER_PATH, v.Name, filename), &buf, int64(buf.Len()), "image/jpeg")
 		}
 
-		storiesResponse, err := ss.ProcessStoryResponseFilter(ctx, tx, *stories)
+		if err != nil {
+			// remove the variants that already uploaded
+			for _, uploaded := range COVER_VARIANTS[:i] {
+				fs.Delete(ctx, path.Join(COVER_PATH, uploaded.Name, filename))
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Remove every variant of the cover image from the storage.
+// Failing to remove the file is not failing the request, only logged.
+func (ss *storyService) removeCover(ctx context.Context, filename string) {
+	if filename == "" {
+		return
+	}
+
+	for _, v := range COVER_VARIANTS {
+		err := ss.fileService.Delete(ctx, path.Join(COVER_PATH, v.Name, filename))
+		if err != nil {
+			log.Println("failed to remove cover", v.Name, filename, err)
+		}
+	}
+}
+
+// Get the cover URL of every variant, nil if the story has no cover.
+func (ss *storyService) coverURLs(ctx context.Context, s entity.Story) (*model.CoverResponse, error) {
+	if s.Cover == "" {
+		return nil, nil
+	}
+
+	urls := make(map[string]string)
+	for _, v := range COVER_VARIANTS {
+		url, err := ss.fileService.URL(ctx, s.CoverPath(v.Name))
 		if err != nil {
 			return nil, err
 		}
 
-		return storiesResponse, nil
+		urls[v.Name] = url
+	}
+
+	return &model.CoverResponse{
+		Thumbnail: urls["thumbnail"],
+		Card:      urls["card"],
+		Full:      urls["full"],
+	}, nil
+}
+
+// Checking the variant is one of the cover variants.
+func isCoverVariant(variant string) bool {
+	for _, v := range COVER_VARIANTS {
+		if v.Name == variant {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Trim the extra fetched story and build the pagination meta.
+// The value is the sorted column value used on the keyset cursor.
+func paginateStories(page pagination.Page, stories []entity.Story, total uint64, value func(s entity.Story) uint64) ([]entity.Story, *pagination.Meta) {
+	rows, hasMore := pagination.Trim(page, stories)
+
+	var cursors []pagination.Cursor
+	for _, s := range rows {
+		cursors = append(cursors, pagination.Cursor{Value: value(s), ID: s.Id})
 	}
+
+	return rows, pagination.NewMeta(page, total, hasMore, cursors)
+}
+
+// Parse the scheduled publish time of the request.
+// Scheduled story is kept as draft until the scheduler publish it.
+func schedulePublish(publishAt string) (sql.NullInt64, error) {
+	if publishAt == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	t, err := time.ParseToUnixTimestamp(model.PUBLISH_AT_LAYOUT, publishAt)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+
+	return sql.NullInt64{Int64: int64(t), Valid: true}, nil
 }
 
-func NewService(storyRepository story.StoryRepository, cr chapter.ChapterRepository, cs chapterservice.ChapterService, db *sql.DB) StoryService {
+func NewService(storyRepository story.StoryRepository, cr chapter.ChapterRepository, cs chapterservice.ChapterService, fs file.FileService, db *sql.DB) StoryService {
 	return &storyService{
 		storyRepository:   storyRepository,
-		fileService:       file.NewService(COVER_PATH),
+		fileService:       fs,
 		chapterRepository: cr,
 		chapterService:    cs,
 		db:                db,
@@ -3,21 +3,26 @@ package story
 import (
 	"context"
 	"database/sql"
+	"io"
 
 	"github.com/mrizkimaulidan/storial/internal/entity"
 	model "github.com/mrizkimaulidan/storial/internal/model/story"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
 )
 
 type StoryService interface {
 	AddStory(ctx context.Context, r model.CreateStoryRequest) (*model.CreatedStoryResponse, error)
 	EditStory(ctx context.Context, r model.UpdateStoryRequest) (*model.UpdatedStoryResponse, error)
-	LoadStoryImageCover(ctx context.Context, filename string) ([]byte, error)
+	LoadStoryImageCover(ctx context.Context, filename string, variant string) ([]byte, error)
 	RemoveStory(ctx context.Context, r model.DeleteStoryRequest) (*model.DeletetedStoryResponse, error)
-	GetAllStory(ctx context.Context, userID uint64) (*[]model.StoryResponse, error)
-	GetStoryBySlug(ctx context.Context, slug string) (*model.StoryResponseBySlug, error)
-	FilterStory(ctx context.Context, filterType string) (*[]model.StoryResponseByFilter, error)
-	GetStoryByCategorySlug(ctx context.Context, categorySlug string) (*[]model.StoryResponseByCategorySlug, error)
-	FilterStoryByCategorySlug(ctx context.Context, categorySlug string, filterType string) (*[]model.StoryResponseByCategorySlug, error)
-	ProcessStoryResponseFilterByCategorySlug(ctx context.Context, tx *sql.Tx, stories []entity.Story) (*[]model.StoryResponseByCategorySlug, error)
-	ProcessStoryResponseFilter(ctx context.Context, tx *sql.Tx, stories []entity.Story) (*[]model.StoryResponseByFilter, error)
+	GetAllStory(ctx context.Context, userID uint64, r pagination.Request) (*[]model.StoryResponse, *pagination.Meta, error)
+	GetStoryBySlug(ctx context.Context, viewer entity.Viewer, slug string) (*model.StoryResponseBySlug, error)
+	PrepareExport(ctx context.Context, viewer entity.Viewer, slug string) (*model.StoryExport, error)
+	WriteEpub(ctx context.Context, e model.StoryExport, w io.Writer) error
+	FilterStory(ctx context.Context, viewer entity.Viewer, filterType string, r pagination.Request) (*[]model.StoryResponseByFilter, *pagination.Meta, error)
+	GetFeed(ctx context.Context, viewer entity.Viewer, r pagination.Request) (*[]model.StoryResponseByFilter, *pagination.Meta, error)
+	GetStoryByCategorySlug(ctx context.Context, viewer entity.Viewer, categorySlug string, r pagination.Request) (*[]model.StoryResponseByCategorySlug, *pagination.Meta, error)
+	FilterStoryByCategorySlug(ctx context.Context, viewer entity.Viewer, categorySlug string, filterType string, r pagination.Request) (*[]model.StoryResponseByCategorySlug, *pagination.Meta, error)
+	ProcessStoryResponseFilterByCategorySlug(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, stories []entity.Story) (*[]model.StoryResponseByCategorySlug, error)
+	ProcessStoryResponseFilter(ctx context.Context, tx *sql.Tx, viewer entity.Viewer, stories []entity.Story) (*[]model.StoryResponseByFilter, error)
 }
@@ -0,0 +1,368 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	gotime "time"
+
+	"github.com/mrizkimaulidan/storial/internal/database"
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	model "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/story"
+	"github.com/mrizkimaulidan/storial/internal/repository/user"
+	exception "github.com/mrizkimaulidan/storial/pkg/exception/user"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+type userService struct {
+	userRepository    user.UserRepository
+	storyRepository   story.StoryRepository
+	chapterRepository chapter.ChapterRepository
+	db                *sql.DB
+}
+
+func (us *userService) GetProfileByUsername(ctx context.Context, username string) (*model.UserResponseByUsername, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	u, err := us.userRepository.FindByUsername(ctx, tx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	storyCounts, err := us.storyRepository.CountStoryByUserID(ctx, tx, u.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	chapterCounts, err := us.chapterRepository.CountChapterByUserID(ctx, tx, u.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	followerCounts, err := us.userRepository.CountFollowers(ctx, tx, u.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	followingCounts, err := us.userRepository.CountFollowing(ctx, tx, u.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserResponseByUsername{
+		Id:              u.Id,
+		Name:            u.Name,
+		Username:        u.Username,
+		Sex:             u.GetGenderName(),
+		Role:            u.Role,
+		Bio:             u.Bio.String,
+		Twitter:         u.Twitter.String,
+		Instagram:       u.Instagram.String,
+		Facebook:        u.Facebook.String,
+		StoryCounts:     *storyCounts,
+		ChapterCounts:   *chapterCounts,
+		FollowerCounts:  *followerCounts,
+		FollowingCounts: *followingCounts,
+		CreatedAt:       time.UnixToTime(u.CreatedAt),
+	}, nil
+}
+
+// Edit the profile of the user. The adult content setting is applied
+// on the user next access token, same as the role.
+func (us *userService) EditProfile(ctx context.Context, r model.UpdateProfileRequest) (*model.UpdatedProfileResponse, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := us.userRepository.FindByID(ctx, tx, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the fields on request are changed
+	if r.Name != nil {
+		u.Name = *r.Name
+	}
+
+	if r.Sex != nil {
+		sex, err := strconv.Atoi(*r.Sex)
+		if err != nil {
+			return nil, err
+		}
+
+		u.Sex = uint8(sex)
+	}
+
+	if r.DateOfBirth != nil {
+		u.DateOfBirth = &sql.NullInt64{}
+		if *r.DateOfBirth != "" {
+			t, err := gotime.Parse(model.DATE_OF_BIRTH_LAYOUT, *r.DateOfBirth)
+			if err != nil {
+				return nil, err
+			}
+
+			u.DateOfBirth = &sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+		}
+	}
+
+	setNullString(&u.Bio, r.Bio)
+	setNullString(&u.PhoneNumber, r.PhoneNumber)
+	setNullString(&u.Twitter, r.Twitter)
+	setNullString(&u.Instagram, r.Instagram)
+	setNullString(&u.Facebook, r.Facebook)
+
+	if r.ShowAdultContent != nil {
+		u.ShowAdultContent = *r.ShowAdultContent == "1"
+	}
+
+	// adult content is only shown to the verified adult, the opt in is
+	// turned off when the date of birth no longer verify the age
+	if u.ShowAdultContent && !u.IsAdultAt(gotime.Now()) {
+		if r.ShowAdultContent != nil {
+			return nil, exception.ErrAgeNotVerified
+		}
+
+		u.ShowAdultContent = false
+	}
+
+	updatedUser, err := us.userRepository.UpdateProfile(ctx, tx, *u)
+	if err != nil {
+		return nil, err
+	}
+
+	return profileResponse(*updatedUser), nil
+}
+
+// Change the role of the user. The new role is applied on the user
+// next access token, the current token keeps the old role until expired.
+func (us *userService) EditRole(ctx context.Context, r model.UpdateRoleRequest) (*model.UpdatedRoleResponse, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	err = r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := us.userRepository.FindByUsername(ctx, tx, r.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	// prevent the admin locking themselves out
+	if u.Id == r.ActorID {
+		return nil, exception.ErrCannotChangeOwnRole
+	}
+
+	err = us.userRepository.UpdateRole(ctx, tx, u.Id, r.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdatedRoleResponse{
+		Id:       u.Id,
+		Username: u.Username,
+		Role:     r.Role,
+	}, nil
+}
+
+func (us *userService) Follow(ctx context.Context, r model.FollowRequest) (*model.FollowResponse, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	followee, err := us.userRepository.FindByUsername(ctx, tx, r.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if followee.Id == r.FollowerID {
+		return nil, exception.ErrCannotFollowYourself
+	}
+
+	err = us.userRepository.Follow(ctx, tx, entity.UserFollow{
+		FollowerID: r.FollowerID,
+		FolloweeID: followee.Id,
+		CreatedAt:  time.CurrentTimeToUnixTimestamp(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FollowResponse{
+		Following: true,
+	}, nil
+}
+
+func (us *userService) Unfollow(ctx context.Context, r model.FollowRequest) (*model.FollowResponse, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	followee, err := us.userRepository.FindByUsername(ctx, tx, r.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = us.userRepository.Unfollow(ctx, tx, r.FollowerID, followee.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FollowResponse{
+		Following: false,
+	}, nil
+}
+
+func (us *userService) GetFollowers(ctx context.Context, username string, r pagination.Request) (*[]model.UserResponseByFollow, *pagination.Meta, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := us.userRepository.FindByUsername(ctx, tx, username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	follows, err := us.userRepository.FindFollowers(ctx, tx, u.Id, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := us.userRepository.CountFollowers(ctx, tx, u.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *follows)
+
+	var cursors []pagination.Cursor
+	var usersResponse []model.UserResponseByFollow
+	for _, f := range rows {
+		cursors = append(cursors, pagination.Cursor{Value: f.CreatedAt, ID: f.FollowerID})
+		usersResponse = append(usersResponse, followResponse(f.Follower, f.CreatedAt))
+	}
+
+	return &usersResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+func (us *userService) GetFollowing(ctx context.Context, username string, r pagination.Request) (*[]model.UserResponseByFollow, *pagination.Meta, error) {
+	tx, err := us.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.CommitOrRollback(tx)
+
+	page, err := r.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := us.userRepository.FindByUsername(ctx, tx, username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	follows, err := us.userRepository.FindFollowing(ctx, tx, u.Id, *page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := us.userRepository.CountFollowing(ctx, tx, u.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore := pagination.Trim(*page, *follows)
+
+	var cursors []pagination.Cursor
+	var usersResponse []model.UserResponseByFollow
+	for _, f := range rows {
+		cursors = append(cursors, pagination.Cursor{Value: f.CreatedAt, ID: f.FolloweeID})
+		usersResponse = append(usersResponse, followResponse(f.Followee, f.CreatedAt))
+	}
+
+	return &usersResponse, pagination.NewMeta(*page, *total, hasMore, cursors), nil
+}
+
+func followResponse(u entity.User, followedAt uint64) model.UserResponseByFollow {
+	return model.UserResponseByFollow{
+		Id:         u.Id,
+		Name:       u.Name,
+		Username:   u.Username,
+		FollowedAt: time.UnixToTime(followedAt),
+	}
+}
+
+// Set the nullable field from request value.
+// Nil value keeps the field and empty string sets the field to NULL.
+func setNullString(field **sql.NullString, value *string) {
+	if value == nil {
+		return
+	}
+
+	*field = &sql.NullString{String: *value, Valid: *value != ""}
+}
+
+// Create the full profile response, only returned to the owner.
+func profileResponse(u entity.User) *model.UpdatedProfileResponse {
+	var dateOfBirth string
+	if u.DateOfBirth.Valid {
+		dateOfBirth = gotime.UnixMilli(u.DateOfBirth.Int64).UTC().Format(model.DATE_OF_BIRTH_LAYOUT)
+	}
+
+	return &model.UpdatedProfileResponse{
+		Id:          u.Id,
+		Name:        u.Name,
+		Username:    u.Username,
+		Email:       u.Email,
+		Sex:         u.GetGenderName(),
+		Role:        u.Role,
+		Bio:         u.Bio.String,
+		DateOfBirth: dateOfBirth,
+		PhoneNumber: u.PhoneNumber.String,
+		Twitter:     u.Twitter.String,
+		Instagram:   u.Instagram.String,
+		Facebook:    u.Facebook.String,
+		CreatedAt:   time.UnixToTime(u.CreatedAt),
+
+		ShowAdultContent: u.ShowAdultContent,
+	}
+}
+
+func NewService(ur user.UserRepository, sr story.StoryRepository, cr chapter.ChapterRepository, db *sql.DB) UserService {
+	return &userService{
+		userRepository:    ur,
+		storyRepository:   sr,
+		chapterRepository: cr,
+		db:                db,
+	}
+}
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"context"
+
+	model "github.com/mrizkimaulidan/storial/internal/model/user"
+	"github.com/mrizkimaulidan/storial/pkg/pagination"
+)
+
+type UserService interface {
+	GetProfileByUsername(ctx context.Context, username string) (*model.UserResponseByUsername, error)
+	EditProfile(ctx context.Context, r model.UpdateProfileRequest) (*model.UpdatedProfileResponse, error)
+	EditRole(ctx context.Context, r model.UpdateRoleRequest) (*model.UpdatedRoleResponse, error)
+	Follow(ctx context.Context, r model.FollowRequest) (*model.FollowResponse, error)
+	Unfollow(ctx context.Context, r model.FollowRequest) (*model.FollowResponse, error)
+	GetFollowers(ctx context.Context, username string, r pagination.Request) (*[]model.UserResponseByFollow, *pagination.Meta, error)
+	GetFollowing(ctx context.Context, username string, r pagination.Request) (*[]model.UserResponseByFollow, *pagination.Meta, error)
+}
@@ -0,0 +1,279 @@
+package tracker
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	gotime "time"
+
+	"github.com/mrizkimaulidan/storial/internal/entity"
+	"github.com/mrizkimaulidan/storial/internal/repository/chapter"
+	"github.com/mrizkimaulidan/storial/internal/repository/library"
+	"github.com/mrizkimaulidan/storial/pkg/time"
+)
+
+// Maximum pending views kept in memory, multiplied by the batch size.
+// Views over the limit are dropped when the database keeps failing.
+var MAX_PENDING_BATCHES = 10
+
+// ViewTracker count the chapter views of the readers. The same reader
+// reading the same chapter is only counted once within the window.
+// Counted views are buffered in memory and saved in batches on every
+// interval, or once the buffer reach the batch size.
+//
+// The view of signed in reader is also the reader progress on the story,
+// only the latest chapter read on each story is kept and saved on the same flush,
+// so reading the chapter does not write to the database.
+//
+// Deduplication is kept in memory, every running server counts on its own.
+type ViewTracker struct {
+	chapterRepository chapter.ChapterRepository
+	libraryRepository library.LibraryRepository
+	db                *sql.DB
+	window            uint64
+	interval          gotime.Duration
+	batchSize         int
+
+	mu       sync.Mutex
+	seen     map[string]uint64
+	pending  []entity.ChapterView
+	progress map[string]entity.ReadingProgress
+	full     chan struct{}
+	done     chan struct{}
+}
+
+// Track the chapter view, ignored when the reader has been counted
+// on the chapter within the window. The reader progress is always updated.
+func (t *ViewTracker) Track(v entity.ChapterView) {
+	key := readerKey(v)
+
+	t.mu.Lock()
+	if v.UserID.Valid {
+		t.trackProgress(entity.ReadingProgress{
+			UserID:    uint64(v.UserID.Int64),
+			StoryID:   v.StoryID,
+			ChapterID: v.ChapterID,
+			UpdatedAt: v.CreatedAt,
+		})
+	}
+
+	last, ok := t.seen[key]
+	if ok && v.CreatedAt < last+t.window {
+		t.mu.Unlock()
+		return
+	}
+
+	t.seen[key] = v.CreatedAt
+	t.pending = append(t.pending, v)
+	full := len(t.pending) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run the tracker on background goroutine until the context canceled.
+// The pending views are flushed once more before stopped.
+func (t *ViewTracker) Start(ctx context.Context) {
+	go func() {
+		defer close(t.done)
+
+		ticker := gotime.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		log.Println("view tracker flushing every", t.interval)
+		for {
+			select {
+			case <-ctx.Done():
+				// not using the canceled context, so the last flush is not aborted
+				t.flush(context.Background())
+				return
+			case <-ticker.C:
+			case <-t.full:
+			}
+
+			t.flush(context.Background())
+		}
+	}()
+}
+
+// Wait until the running tracker stopped.
+func (t *ViewTracker) Wait() {
+	<-t.done
+}
+
+// Keep the latest progress of the reader on the story, must be called with the lock held.
+func (t *ViewTracker) trackProgress(p entity.ReadingProgress) {
+	key := fmt.Sprintf("%d:%d", p.UserID, p.StoryID)
+
+	last, ok := t.progress[key]
+	if ok && last.UpdatedAt > p.UpdatedAt {
+		return
+	}
+
+	t.progress[key] = p
+}
+
+// Save the pending views and progress, then forget the readers counted before the window.
+// When saving failed, they are put back to be retried on the next flush. The rows
+// referring to the deleted chapter, story or user are skipped by the repository,
+// so only the failure of the database itself is retried.
+func (t *ViewTracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	views := t.pending
+	t.pending = nil
+
+	progress := make([]entity.ReadingProgress, 0, len(t.progress))
+	for _, p := range t.progress {
+		progress = append(progress, p)
+	}
+	t.progress = make(map[string]entity.ReadingProgress)
+
+	now := time.CurrentTimeToUnixTimestamp()
+	for key, last := range t.seen {
+		if last+t.window <= now {
+			delete(t.seen, key)
+		}
+	}
+	t.mu.Unlock()
+
+	for len(views) > 0 {
+		n := t.batchSize
+		if len(views) < n {
+			n = len(views)
+		}
+
+		err := t.save(ctx, views[:n])
+		if err != nil {
+			log.Println("[ERROR] view tracker", err)
+			t.requeue(views)
+			break
+		}
+
+		views = views[n:]
+	}
+
+	for len(progress) > 0 {
+		n := t.batchSize
+		if len(progress) < n {
+			n = len(progress)
+		}
+
+		err := t.saveProgress(ctx, progress[:n])
+		if err != nil {
+			log.Println("[ERROR] view tracker", err)
+			t.requeueProgress(progress)
+			return
+		}
+
+		progress = progress[n:]
+	}
+}
+
+// Save single batch of views inside single transaction.
+func (t *ViewTracker) save(ctx context.Context, views []entity.ChapterView) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = t.chapterRepository.SaveChapterViews(ctx, tx, views)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Save single batch of reader progress inside single transaction.
+func (t *ViewTracker) saveProgress(ctx context.Context, progress []entity.ReadingProgress) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = t.libraryRepository.SaveReadChapters(ctx, tx, progress)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Put the unsaved views back before the views tracked in the meantime.
+func (t *ViewTracker) requeue(views []entity.ChapterView) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending := make([]entity.ChapterView, 0, len(views)+len(t.pending))
+	pending = append(pending, views...)
+	pending = append(pending, t.pending...)
+
+	limit := t.batchSize * MAX_PENDING_BATCHES
+	if len(pending) > limit {
+		log.Printf("[ERROR] view tracker dropped %d views", len(pending)-limit)
+		pending = pending[len(pending)-limit:]
+	}
+
+	t.pending = pending
+}
+
+// Put the unsaved progress back, unless the reader has read another chapter in the meantime.
+// The progress is kept per reader and story, so it is bounded by the readers.
+func (t *ViewTracker) requeueProgress(progress []entity.ReadingProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, p := range progress {
+		t.trackProgress(p)
+	}
+}
+
+// Identify the reader of the view on the chapter.
+func readerKey(v entity.ChapterView) string {
+	if v.UserID.Valid {
+		return fmt.Sprintf("%d:user:%d", v.ChapterID, v.UserID.Int64)
+	}
+
+	return fmt.Sprintf("%d:client:%s", v.ChapterID, v.Fingerprint.String)
+}
+
+// Fingerprint of the anonymous client, hashed from the client IP
+// and the user agent.
+func Fingerprint(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	sum := sha256.Sum256([]byte(ip + "|" + r.UserAgent()))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func New(cr chapter.ChapterRepository, lr library.LibraryRepository, db *sql.DB, window gotime.Duration, interval gotime.Duration, batchSize int) *ViewTracker {
+	return &ViewTracker{
+		chapterRepository: cr,
+		libraryRepository: lr,
+		db:                db,
+		window:            uint64(window.Milliseconds()),
+		interval:          interval,
+		batchSize:         batchSize,
+		seen:              make(map[string]uint64),
+		progress:          make(map[string]entity.ReadingProgress),
+		full:              make(chan struct{}, 1),
+		done:              make(chan struct{}),
+	}
+}
@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// Maximum edit distance computed by Myers algorithm. Larger changes,
+	// such as text being replaced entirely, are reported as full replacement.
+	MAX_EDIT_DISTANCE = 2000
+
+	DEFAULT_CONTEXT = 3
+)
+
+// Kind of line operation.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Single line of the edit script.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Split text into lines. The trailing newline is not counted as an empty line.
+func SplitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Compute the line edits needed to change a into b.
+func Lines(a, b []string) []Line {
+	// the common prefix and suffix are not part of the edit distance
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for _, t := range a[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: t})
+	}
+
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, t := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: t})
+	}
+
+	return lines
+}
+
+// Create unified diff of the text a and b. The names are used as the
+// file headers and context is how many unchanged lines around the changes.
+// Returning empty string when both text are equal.
+func Unified(aName, bName, a, b string, context int) string {
+	lines := Lines(SplitLines(a), SplitLines(b))
+
+	// line numbers before every edit line
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+	for i, l := range lines {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if l.Op != Insert {
+			aPos[i+1]++
+		}
+
+		if l.Op != Delete {
+			bPos[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			i++
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// extend the hunk over the changes separated by short unchanged lines
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+
+			j := end
+			for j < len(lines) && lines[j].Op == Equal {
+				j++
+			}
+
+			if j == len(lines) || j-end > 2*context {
+				end += context
+				if end > len(lines) {
+					end = len(lines)
+				}
+
+				break
+			}
+
+			end = j
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aPos[start], aPos[end]-aPos[start]), hunkRange(bPos[start], bPos[end]-bPos[start]))
+		for _, l := range lines[start:end] {
+			switch l.Op {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+
+			out.WriteString(l.Text)
+			out.WriteString("\n")
+		}
+
+		i = end
+	}
+
+	return out.String()
+}
+
+// Format hunk range, the start line is one based unless the range is empty.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// Compute the shortest edit script using Myers algorithm.
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	var trace [][]int
+	found := false
+	for d := 0; d <= max && d <= MAX_EDIT_DISTANCE; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			break
+		}
+	}
+
+	if !found {
+		return replace(a, b)
+	}
+
+	var lines []Line
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			lines = append(lines, Line{Op: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if x == prevX {
+			lines = append(lines, Line{Op: Insert, Text: b[y-1]})
+			y--
+		} else {
+			lines = append(lines, Line{Op: Delete, Text: a[x-1]})
+			x--
+		}
+	}
+
+	for x > 0 && y > 0 {
+		lines = append(lines, Line{Op: Equal, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return lines
+}
+
+// Edit script that delete every line of a and insert every line of b.
+func replace(a, b []string) []Line {
+	var lines []Line
+	for _, t := range a {
+		lines = append(lines, Line{Op: Delete, Text: t})
+	}
+
+	for _, t := range b {
+		lines = append(lines, Line{Op: Insert, Text: t})
+	}
+
+	return lines
+}
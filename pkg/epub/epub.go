@@ -0,0 +1,353 @@
+package epub
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	MEDIA_TYPE       = "application/epub+zip"
+	DEFAULT_LANGUAGE = "en"
+
+	// Layout of the dcterms:modified metadata.
+	MODIFIED_LAYOUT = "2006-01-02T15:04:05Z"
+)
+
+var (
+	ErrClosed          = errors.New("epub writer has been closed")
+	ErrCoverNotAllowed = errors.New("epub cover must be added once before the chapters")
+)
+
+// The package must only reference local resources, so the remote images
+// are replaced by their alternative text.
+var imagePattern = regexp.MustCompile(`<img [^>]*?alt="([^"]*)"[^>]*/>`)
+
+var containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var stylesheet = `body { margin: 0 5%; line-height: 1.5; }
+h1 { text-align: center; margin: 2em 0 1em; }
+blockquote { margin: 1em 2em; font-style: italic; }
+pre { white-space: pre-wrap; }
+.title-page { text-align: center; margin-top: 30%; }
+.cover { text-align: center; }
+.cover img { max-width: 100%; max-height: 100%; }
+`
+
+// Metadata of the book.
+type Metadata struct {
+	Identifier  string
+	Title       string
+	Author      string
+	Description string
+	Language    string
+	Modified    time.Time
+}
+
+// Document of the book listed on the package manifest and spine.
+type document struct {
+	ID        string
+	Href      string
+	Title     string
+	MediaType string
+}
+
+// Writer streams EPUB 3 package into the underlying writer.
+// Every document is written as soon as it is added, only the table of
+// contents is kept, so the package document and the navigation document
+// are written last on Close.
+type Writer struct {
+	zip       *zip.Writer
+	metadata  Metadata
+	cover     *document
+	documents []document
+	chapters  int
+	closed    bool
+}
+
+// Add the cover image, the image is copied from the reader.
+// Only single cover can be added before the chapters.
+func (w *Writer) AddCover(r io.Reader, mediaType string, extension string) error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	if w.cover != nil || len(w.documents) > 0 {
+		return ErrCoverNotAllowed
+	}
+
+	href := "cover." + extension
+	f, err := w.zip.Create("OEBPS/" + href)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+
+	w.cover = &document{ID: "cover-image", Href: href, MediaType: mediaType}
+
+	body := fmt.Sprintf("<div class=\"cover\"><img src=\"%s\" alt=\"%s\" /></div>\n", href, escape(w.metadata.Title))
+	return w.writeDocument(document{ID: "cover", Href: "cover.xhtml", Title: "Cover"}, "cover", body)
+}
+
+// Add the chapter, the body is XHTML fragment.
+// The chapters are ordered as they are added.
+func (w *Writer) AddChapter(title string, body string) error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	err := w.writeTitlePage()
+	if err != nil {
+		return err
+	}
+
+	w.chapters++
+	id := fmt.Sprintf("chapter-%04d", w.chapters)
+	body = fmt.Sprintf("<h1>%s</h1>\n%s", escape(title), Sanitize(body))
+
+	return w.writeDocument(document{ID: id, Href: id + ".xhtml", Title: title}, "chapter", body)
+}
+
+// Write the navigation document and the package document, then finish the archive.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	err := w.writeTitlePage()
+	if err != nil {
+		return err
+	}
+
+	w.closed = true
+
+	err = w.writeFile("OEBPS/nav.xhtml", w.nav())
+	if err != nil {
+		return err
+	}
+
+	err = w.writeFile("OEBPS/content.opf", w.packageDocument())
+	if err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
+
+// Write the title page showing the title, the author and the description
+// after the cover, once.
+func (w *Writer) writeTitlePage() error {
+	for _, d := range w.documents {
+		if d.ID == "title-page" {
+			return nil
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<div class=\"title-page\">\n<h1>%s</h1>\n", escape(w.metadata.Title))
+	if w.metadata.Author != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", escape(w.metadata.Author))
+	}
+	if w.metadata.Description != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", escape(w.metadata.Description))
+	}
+	b.WriteString("</div>\n")
+
+	return w.writeDocument(document{ID: "title-page", Href: "title-page.xhtml", Title: w.metadata.Title}, "titlepage", b.String())
+}
+
+// Write XHTML content document and add it to the spine.
+func (w *Writer) writeDocument(d document, epubType string, body string) error {
+	f, err := w.zip.Create("OEBPS/" + d.Href)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(f, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">
+<head>
+<meta charset="UTF-8" />
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="style.css" />
+</head>
+<body>
+<section epub:type="%s">
+%s</section>
+</body>
+</html>
+`, escape(w.metadata.Language), escape(w.metadata.Language), escape(d.Title), epubType, body)
+	if err != nil {
+		return err
+	}
+
+	w.documents = append(w.documents, d)
+
+	return nil
+}
+
+func (w *Writer) writeFile(name string, content string) error {
+	f, err := w.zip.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// Build the navigation document listing the chapters,
+// or the title page when the book has no chapter.
+func (w *Writer) nav() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">
+<head>
+<meta charset="UTF-8" />
+<title>%s</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>%s</h1>
+<ol>
+`, escape(w.metadata.Language), escape(w.metadata.Language), escape(w.metadata.Title), escape(w.metadata.Title))
+
+	for _, d := range w.documents {
+		if d.ID == "cover" || d.ID == "title-page" && w.chapters > 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", d.Href, escape(d.Title))
+	}
+
+	b.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+
+	return b.String()
+}
+
+// Build the package document holding the metadata, the manifest and the spine.
+func (w *Writer) packageDocument() string {
+	m := w.metadata
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="%s">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">%s</dc:identifier>
+<dc:title>%s</dc:title>
+<dc:language>%s</dc:language>
+`, escape(m.Language), escape(m.Identifier), escape(m.Title), escape(m.Language))
+
+	if m.Author != "" {
+		fmt.Fprintf(&b, "<dc:creator>%s</dc:creator>\n", escape(m.Author))
+	}
+	if m.Description != "" {
+		fmt.Fprintf(&b, "<dc:description>%s</dc:description>\n", escape(m.Description))
+	}
+	if w.cover != nil {
+		b.WriteString("<meta name=\"cover\" content=\"cover-image\" />\n")
+	}
+
+	fmt.Fprintf(&b, "<meta property=\"dcterms:modified\">%s</meta>\n</metadata>\n<manifest>\n", m.Modified.UTC().Format(MODIFIED_LAYOUT))
+	b.WriteString("<item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\" />\n")
+	b.WriteString("<item id=\"style\" href=\"style.css\" media-type=\"text/css\" />\n")
+	if w.cover != nil {
+		fmt.Fprintf(&b, "<item id=\"%s\" href=\"%s\" media-type=\"%s\" properties=\"cover-image\" />\n", w.cover.ID, w.cover.Href, escape(w.cover.MediaType))
+	}
+	for _, d := range w.documents {
+		fmt.Fprintf(&b, "<item id=\"%s\" href=\"%s\" media-type=\"application/xhtml+xml\" />\n", d.ID, d.Href)
+	}
+
+	b.WriteString("</manifest>\n<spine>\n")
+	for _, d := range w.documents {
+		fmt.Fprintf(&b, "<itemref idref=\"%s\" />\n", d.ID)
+	}
+	b.WriteString("</spine>\n</package>\n")
+
+	return b.String()
+}
+
+// Sanitize the XHTML fragment to be included in the package.
+// Remote images are replaced by their alternative text and the characters
+// not allowed in XML are removed.
+func Sanitize(body string) string {
+	body = imagePattern.ReplaceAllString(body, "$1")
+
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 && r != 0xFFFE && r != 0xFFFF {
+			return r
+		}
+
+		return -1
+	}, body)
+}
+
+func escape(s string) string {
+	return html.EscapeString(Sanitize(s))
+}
+
+// Create writer streaming the package into w. The mimetype, the container
+// and the title page are written immediately.
+func NewWriter(w io.Writer, m Metadata) (*Writer, error) {
+	if m.Language == "" {
+		m.Language = DEFAULT_LANGUAGE
+	}
+
+	if m.Modified.IsZero() {
+		m.Modified = time.Now()
+	}
+
+	ew := &Writer{
+		zip:      zip.NewWriter(w),
+		metadata: m,
+	}
+
+	// the mimetype must be the first entry, stored without compression
+	// and without data descriptor
+	mimetype := []byte(MEDIA_TYPE)
+	f, err := ew.zip.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = f.Write(mimetype)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ew.writeFile("META-INF/container.xml", containerXML)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ew.writeFile("OEBPS/style.css", stylesheet)
+	if err != nil {
+		return nil, err
+	}
+
+	return ew, nil
+}
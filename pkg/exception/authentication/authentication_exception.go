@@ -8,4 +8,11 @@ var (
 
 	ErrEmailNotFound    = errors.New("email not found")
 	ErrPasswordAreWrong = errors.New("password are wrong")
+
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrForbidden           = errors.New("you do not have permission to access this resource")
 )
@@ -0,0 +1,11 @@
+package category
+
+import "errors"
+
+var (
+	ErrCategoryNotFound         = errors.New("category not found")
+	ErrCategorySlugNotAvailable = errors.New("category slug is not available")
+	ErrCategoryHasStories       = errors.New("category still has stories")
+	ErrInvalidReassignCategory  = errors.New("stories must be reassigned to another category")
+	ErrInvalidCategoryOrder     = errors.New("category order must contain every category exactly once")
+)
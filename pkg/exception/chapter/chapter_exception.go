@@ -5,4 +5,8 @@ import "errors"
 var (
 	ErrChapterNotFound          = errors.New("chapter not found")
 	ErrCannotLikeYourOwnChapter = errors.New("cannot like your own chapter")
+	ErrInvalidChapterOrder      = errors.New("chapter order must contain every chapter of the story exactly once")
+	ErrRevisionNotFound         = errors.New("chapter revision not found")
+	ErrInvalidFormat            = errors.New("format must be source or html")
+	ErrSlugTaken                = errors.New("chapter slug is taken by another chapter, please try again")
 )
@@ -0,0 +1,9 @@
+package comment
+
+import "errors"
+
+var (
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrNotAllowedToEdit   = errors.New("not allowed to edit this comment")
+	ErrNotAllowedToDelete = errors.New("not allowed to delete this comment")
+)
@@ -0,0 +1,10 @@
+package file
+
+import "errors"
+
+var (
+	ErrFileNotFound          = errors.New("file not found")
+	ErrInvalidFileKey        = errors.New("invalid file key")
+	ErrUnknownStorageDriver  = errors.New("unknown storage driver")
+	ErrStorageRequestFailure = errors.New("storage request failure")
+)
@@ -0,0 +1,7 @@
+package library
+
+import "errors"
+
+var (
+	ErrProgressNotFound = errors.New("reading progress not found")
+)
@@ -4,5 +4,22 @@ import "errors"
 
 var (
 	ErrStoryNotFound      = errors.New("story not found")
+	ErrSlugTaken          = errors.New("story slug is taken by another story, please try again")
 	ErrCoverImageNotFound = errors.New("cover image not found")
+	ErrCoverImageInvalid  = errors.New("cover must be a JPEG, PNG or WebP image")
+	ErrCoverImageTooLarge = errors.New("cover image is too large")
+
+	ErrAdultContentRestricted = errors.New("adult content is only available to verified adults who opted in")
 )
+
+// Error returned when the requested slug belonged to a renamed story
+// or chapter, holding the current slugs to redirect to.
+// Chapter slug is empty when only the story is requested.
+type SlugMovedError struct {
+	StorySlug   string
+	ChapterSlug string
+}
+
+func (e *SlugMovedError) Error() string {
+	return "slug has been moved"
+}
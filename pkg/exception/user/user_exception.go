@@ -0,0 +1,10 @@
+package user
+
+import "errors"
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrCannotFollowYourself = errors.New("cannot follow yourself")
+	ErrCannotChangeOwnRole  = errors.New("cannot change your own role")
+	ErrAgeNotVerified       = errors.New("date of birth must be at least 18 years ago to show adult content")
+)
@@ -0,0 +1,133 @@
+package highlight
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+var (
+	MARK_OPEN  = "<mark>"
+	MARK_CLOSE = "</mark>"
+	ELLIPSIS   = "…"
+)
+
+// Split search query into lowercase terms.
+// Anything other than letter and digit is a separator.
+func Terms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		if !seen[f] {
+			seen[f] = true
+			terms = append(terms, f)
+		}
+	}
+
+	return terms
+}
+
+// Create HTML escaped snippet of the text around the first matched term,
+// every matched term inside the snippet is wrapped with mark tag.
+// The radius is how many characters kept before and after the first match.
+func Snippet(text string, terms []string, radius int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matches := find(lower, terms)
+
+	start, end := 0, len(runes)
+	if len(matches) > 0 {
+		start = matches[0][0] - radius
+		end = matches[0][1] + radius
+	} else {
+		end = radius * 2
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	// move the boundaries to whitespace, so the words are not cut
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ELLIPSIS)
+	}
+
+	pos := start
+	for _, m := range matches {
+		if m[0] < start || m[1] > end {
+			continue
+		}
+
+		b.WriteString(html.EscapeString(string(runes[pos:m[0]])))
+		b.WriteString(MARK_OPEN)
+		b.WriteString(html.EscapeString(string(runes[m[0]:m[1]])))
+		b.WriteString(MARK_CLOSE)
+		pos = m[1]
+	}
+
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(ELLIPSIS)
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// Find non overlapping ranges of the terms on the text ordered by position.
+func find(text []rune, terms []string) [][2]int {
+	var matches [][2]int
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, t := range terms {
+			tr := []rune(t)
+			if len(tr) > matched && hasPrefix(text[i:], tr) {
+				matched = len(tr)
+			}
+		}
+
+		if matched > 0 {
+			matches = append(matches, [2]int{i, i + matched})
+			i += matched
+			continue
+		}
+
+		i++
+	}
+
+	return matches
+}
+
+func hasPrefix(s []rune, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
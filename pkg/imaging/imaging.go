@@ -0,0 +1,116 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	JPEG_QUALITY = 85
+
+	// Maximum width and height of the decoded image,
+	// preventing huge image allocating too much memory.
+	MAX_DIMENSION = 8000
+
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrFileTooLarge      = errors.New("image file is too large")
+	ErrDimensionTooLarge = errors.New("image dimension is too large")
+)
+
+// Allowed image content types, sniffed from the file content
+// instead of trusting the extension or the request header.
+var allowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Decode JPEG, PNG or WebP image from reader.
+// Reading more than maxSize bytes throwing an err file too large.
+// Returning the image and the sniffed content type.
+func Decode(r io.Reader, maxSize int64) (image.Image, string, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if int64(len(b)) > maxSize {
+		return nil, "", ErrFileTooLarge
+	}
+
+	contentType := http.DetectContentType(b)
+	if !allowedContentTypes[contentType] {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	if cfg.Width > MAX_DIMENSION || cfg.Height > MAX_DIMENSION {
+		return nil, "", ErrDimensionTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	return img, contentType, nil
+}
+
+// Resize the image to fit inside width x height box keeping the aspect ratio.
+// The image is never upscaled.
+func Fit(img image.Image, width int, height int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w <= width && h <= height {
+		return img
+	}
+
+	// compare the ratio w/h against width/height without float
+	if w*height > h*width {
+		h = h * width / w
+		w = width
+	} else {
+		w = w * height / h
+		h = height
+	}
+
+	if w < 1 {
+		w = 1
+	}
+
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Src, nil)
+
+	return dst
+}
+
+// Encode the image to JPEG. The transparent area is filled with white
+// since JPEG has no alpha channel. Only the pixels are encoded, so any
+// metadata of the original file such as EXIF is not kept.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, dst, &jpeg.Options{Quality: JPEG_QUALITY})
+}
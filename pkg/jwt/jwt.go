@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -16,6 +20,9 @@ var c = config.New().GetConfig()
 var (
 	SECRET_KEY                                      = []byte(c.JWT_SECRET_KEY)
 	CtxKeyUserInformation ContextKeyUserInformation = "userInformation"
+
+	ACCESS_TOKEN_TTL  = 30 * time.Minute
+	REFRESH_TOKEN_TTL = 30 * 24 * time.Hour
 )
 
 // Custom claims for JWT.
@@ -24,17 +31,39 @@ type CustomClaims struct {
 	Id    uint64
 	Name  string
 	Email string
+	Role  string
+	Adult bool
+}
+
+// Get the viewer of the claims, used to check the content visibility.
+func (c *CustomClaims) Viewer() entity.Viewer {
+	return entity.Viewer{
+		UserID: c.Id,
+		Role:   c.Role,
+		Adult:  c.Adult,
+	}
 }
 
 // Generate JSON Web Token.
+// Every token has unique ID (jti) so it can be revoked later.
 func GenerateToken(u entity.User) (string, error) {
+	jti, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := CustomClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * time.Minute)),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ACCESS_TOKEN_TTL)),
 		},
 		Id:    u.Id,
 		Name:  u.Name,
 		Email: u.Email,
+		Role:  u.Role,
+		Adult: u.CanSeeAdultContent(now),
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -46,3 +75,33 @@ func GenerateToken(u entity.User) (string, error) {
 
 	return token, nil
 }
+
+// Generate opaque refresh token.
+// Returning the plain token for the client and the hashes to be persisted.
+func GenerateRefreshToken() (string, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, HashRefreshToken(token), nil
+}
+
+// Hash refresh token to SHA-256 hex format.
+// Only the hashes are stored on database.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate URL safe random string from n random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,749 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Every HTML written in the source is escaped except the allowed inline tags,
+// links and images only keep the allowed URL schemes.
+var (
+	// Raw inline HTML tags kept from the source, only when properly closed
+	// and without attributes.
+	ALLOWED_INLINE_HTML = []string{"u", "sub", "sup", "mark"}
+
+	// URL schemes allowed on links, relative URLs are always allowed.
+	// Images only allow http and https.
+	ALLOWED_SCHEMES = []string{"http", "https", "mailto"}
+
+	// Maximum nesting of the blocks and of the inline elements. The deeper
+	// content is written as text, keeping the rendering time linear.
+	MAX_NESTING = 16
+)
+
+var (
+	headingPattern      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern         = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern        = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	bulletPattern       = regexp.MustCompile(`^( {0,3})([-*+])([ \t]+|$)`)
+	orderedPattern      = regexp.MustCompile(`^( {0,3})([0-9]{1,9})([.)])([ \t]+|$)`)
+	quotePattern        = regexp.MustCompile(`^ {0,3}> ?`)
+	languagePattern     = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+	autolinkPattern     = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*)>`)
+	autoEmailPattern    = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*)>`)
+	inlineHTMLPattern   = regexp.MustCompile(`^<([a-z]+)>`)
+	lineBreakTagPattern = regexp.MustCompile(`^<br ?/?>`)
+	blankLinePattern    = regexp.MustCompile(`\n[ \t]*\n`)
+)
+
+// Render the Markdown source into sanitized HTML.
+// Supported syntax: paragraphs, ATX headings, blockquotes, bullet and
+// ordered lists, fenced code blocks, horizontal rules, emphasis, strong,
+// strikethrough, code spans, links, images, autolinks and hard line breaks.
+func Render(src string) string {
+	lines := strings.Split(normalize(src), "\n")
+
+	return renderBlocks(lines, 0)
+}
+
+// Render plain text into HTML, paragraphs are separated by blank lines
+// and single line breaks are kept.
+func RenderText(src string) string {
+	var b strings.Builder
+	for _, paragraph := range blankLinePattern.Split(normalize(src), -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br />\n"))
+		b.WriteString("</p>\n")
+	}
+
+	return b.String()
+}
+
+// Normalize line endings and tabs.
+func normalize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	return strings.ReplaceAll(src, "\t", "    ")
+}
+
+func renderBlocks(lines []string, depth int) string {
+	var b strings.Builder
+	var paragraph []string
+
+	if depth > MAX_NESTING {
+		return "<p>" + html.EscapeString(strings.TrimSpace(strings.Join(lines, "\n"))) + "</p>\n"
+	}
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>")
+			b.WriteString(renderInline(strings.TrimRight(strings.Join(paragraph, "\n"), " "), 0))
+			b.WriteString("</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case fencePattern.MatchString(line):
+			flush()
+			i = renderFence(&b, lines, i)
+		case headingPattern.MatchString(line):
+			flush()
+			m := headingPattern.FindStringSubmatch(line)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", len(m[1]), renderInline(m[2], 0), len(m[1]))
+		case rulePattern.MatchString(line):
+			flush()
+			b.WriteString("<hr />\n")
+		case quotePattern.MatchString(line):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePattern.ReplaceAllString(lines[i], ""))
+			}
+			i--
+
+			b.WriteString("<blockquote>\n")
+			b.WriteString(renderBlocks(quoted, depth+1))
+			b.WriteString("</blockquote>\n")
+		case bulletPattern.MatchString(line) || orderedPattern.MatchString(line):
+			// list item interrupting paragraph must not be empty
+			if len(paragraph) > 0 && strings.TrimSpace(listContent(line)) == "" {
+				paragraph = append(paragraph, strings.TrimLeft(line, " "))
+				continue
+			}
+
+			flush()
+			i = renderList(&b, lines, i, depth)
+		default:
+			paragraph = append(paragraph, strings.TrimLeft(line, " "))
+		}
+	}
+
+	flush()
+
+	return b.String()
+}
+
+// Render fenced code block starting at the line, returning the last line of the block.
+func renderFence(b *strings.Builder, lines []string, start int) int {
+	m := fencePattern.FindStringSubmatch(lines[start])
+	fence := m[1]
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			break
+		}
+
+		code = append(code, lines[i])
+	}
+
+	b.WriteString("<pre><code")
+	if languagePattern.MatchString(m[2]) {
+		fmt.Fprintf(b, ` class="language-%s"`, m[2])
+	}
+	b.WriteString(">")
+	for _, line := range code {
+		b.WriteString(html.EscapeString(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+
+	return i
+}
+
+// Render list starting at the line, returning the last line of the list.
+// Lines indented under the item belong to the item, so lists can be nested.
+func renderList(b *strings.Builder, lines []string, start int, depth int) int {
+	ordered := orderedPattern.MatchString(lines[start])
+	marker := listMarker(lines[start])
+
+	if ordered {
+		number := strings.TrimLeft(orderedPattern.FindStringSubmatch(lines[start])[2], "0")
+		if number != "1" && number != "" {
+			fmt.Fprintf(b, "<ol start=\"%s\">\n", number)
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	var item []string
+	loose := false
+	writeItem := func() {
+		content := renderBlocks(item, depth+1)
+
+		// tight item is not wrapped with paragraph
+		if !loose && strings.HasPrefix(content, "<p>") && strings.Count(content, "<p>") == 1 {
+			content = strings.Replace(content, "<p>", "", 1)
+			content = strings.Replace(content, "</p>\n", "\n", 1)
+		}
+
+		b.WriteString("<li>")
+		b.WriteString(strings.TrimSuffix(content, "\n"))
+		b.WriteString("</li>\n")
+	}
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if i > start && listMarker(line) == marker && !isIndented(line, 2) {
+			writeItem()
+			item = nil
+		}
+
+		if i == start || listMarker(line) == marker && !isIndented(line, 2) {
+			item = append(item, listContent(line))
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			// blank line ends the list unless the list or the item continues
+			next := i + 1
+			if next < len(lines) && (listMarker(lines[next]) == marker || isIndented(lines[next], 2)) {
+				loose = loose || listMarker(lines[next]) == marker
+				item = append(item, "")
+				continue
+			}
+
+			break
+		}
+
+		if isIndented(line, 2) {
+			item = append(item, dedent(line, 4))
+			continue
+		}
+
+		// lazy continuation of the item paragraph
+		if len(item) > 0 && strings.TrimSpace(item[len(item)-1]) != "" && !startsBlock(line) {
+			item = append(item, strings.TrimSpace(line))
+			continue
+		}
+
+		break
+	}
+
+	writeItem()
+
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+
+	return i - 1
+}
+
+// Get the marker kind of the list item line, empty when it is not a list item.
+// Ordered list markers are identified by the delimiter.
+func listMarker(line string) string {
+	if rulePattern.MatchString(line) {
+		return ""
+	}
+
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		return m[2]
+	}
+
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return "1" + m[3]
+	}
+
+	return ""
+}
+
+// Get the content of the list item line after the marker.
+func listContent(line string) string {
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		return line[len(m[0]):]
+	}
+
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return line[len(m[0]):]
+	}
+
+	return line
+}
+
+// Checking the line starts a block that interrupts a paragraph.
+func startsBlock(line string) bool {
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) || rulePattern.MatchString(line) ||
+		quotePattern.MatchString(line) || listMarker(line) != ""
+}
+
+func isIndented(line string, n int) bool {
+	return strings.HasPrefix(line, strings.Repeat(" ", n))
+}
+
+// Remove up to n leading spaces.
+func dedent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+
+	return line
+}
+
+// State of rendering single inline text. The matching brackets are paired
+// once and the delimiters without closing delimiter are remembered with
+// the first position failed, so the text is not searched again for every
+// later delimiter.
+type inline struct {
+	s      string
+	depth  int
+	pairs  map[int]int
+	failed map[string]int
+}
+
+// Render the inline syntax. Delimiters are only rendered when their
+// closing delimiter is found, so the rendered tags are always balanced.
+// Inline elements nested deeper than the max nesting are written as text.
+func renderInline(s string, depth int) string {
+	if depth > MAX_NESTING {
+		return html.EscapeString(s)
+	}
+
+	in := &inline{
+		s:      s,
+		depth:  depth,
+		pairs:  matchPairs(s),
+		failed: make(map[string]int),
+	}
+
+	return in.render()
+}
+
+func (in *inline) render() string {
+	var b strings.Builder
+	var text strings.Builder
+
+	writeText := func() {
+		b.WriteString(html.EscapeString(text.String()))
+		text.Reset()
+	}
+
+	s := in.s
+	for i := 0; i < len(s); {
+		c := s[i]
+		rest := s[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			writeText()
+			b.WriteString("<br />")
+			i++
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+		case c == '\n':
+			// two trailing spaces make hard line break
+			t := text.String()
+			trimmed := strings.TrimRight(t, " ")
+			hard := len(t)-len(trimmed) >= 2
+			text.Reset()
+			text.WriteString(trimmed)
+			writeText()
+			if hard {
+				b.WriteString("<br />")
+			}
+			b.WriteString("\n")
+			i++
+		case c == '`':
+			n, rendered, ok := in.codeSpan(i)
+			if !ok {
+				n = len(rest) - len(strings.TrimLeft(rest, "`"))
+				text.WriteString(rest[:n])
+				i += n
+				continue
+			}
+
+			writeText()
+			b.WriteString(rendered)
+			i += n
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			closing, end, ok := in.linkBounds(i + 1)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			writeText()
+			b.WriteString(link(s[i+2:closing], s[closing+2:end], true, in.depth))
+			i = end + 1
+		case c == '[':
+			closing, end, ok := in.linkBounds(i)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			writeText()
+			b.WriteString(link(s[i+1:closing], s[closing+2:end], false, in.depth))
+			i = end + 1
+		case c == '<':
+			n, rendered, ok := in.angle(i)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			writeText()
+			b.WriteString(rendered)
+			i += n
+		case c == '*' || c == '_' || c == '~':
+			n, rendered, ok := in.emphasis(i)
+			if !ok {
+				run := len(rest) - len(strings.TrimLeft(rest, string(c)))
+				text.WriteString(rest[:run])
+				i += run
+				continue
+			}
+
+			writeText()
+			b.WriteString(rendered)
+			i += n
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+
+	writeText()
+
+	return b.String()
+}
+
+// Checking the closing delimiter was not found after an earlier position,
+// so it is not found after the position either.
+func (in *inline) hasFailed(delimiter string, i int) bool {
+	p, ok := in.failed[delimiter]
+	return ok && p <= i
+}
+
+// Remember the closing delimiter is not found after the position.
+func (in *inline) fail(delimiter string, i int) {
+	if p, ok := in.failed[delimiter]; !ok || i < p {
+		in.failed[delimiter] = i
+	}
+}
+
+// Pair the brackets and the parentheses by position, escaped ones are skipped.
+func matchPairs(s string) map[int]int {
+	pairs := make(map[int]int)
+
+	var brackets, parentheses []int
+	pair := func(open *[]int, j int) {
+		if len(*open) > 0 {
+			pairs[(*open)[len(*open)-1]] = j
+			*open = (*open)[:len(*open)-1]
+		}
+	}
+
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			brackets = append(brackets, j)
+		case ']':
+			pair(&brackets, j)
+		case '(':
+			parentheses = append(parentheses, j)
+		case ')':
+			pair(&parentheses, j)
+		}
+	}
+
+	return pairs
+}
+
+// Render code span at the position, closed by the same number of backticks.
+// The backtick run without closing run is remembered as failed.
+func (in *inline) codeSpan(i int) (int, string, bool) {
+	rest := in.s[i:]
+	fence := rest[:len(rest)-len(strings.TrimLeft(rest, "`"))]
+	if in.hasFailed(fence, i) {
+		return 0, "", false
+	}
+
+	n, rendered, ok := codeSpan(rest)
+	if !ok {
+		in.fail(fence, i)
+	}
+
+	return n, rendered, ok
+}
+
+// Render code span starting with backticks, closed by the same number of backticks.
+func codeSpan(s string) (int, string, bool) {
+	open := len(s) - len(strings.TrimLeft(s, "`"))
+	fence := s[:open]
+
+	for j := open; j < len(s); {
+		k := strings.Index(s[j:], fence)
+		if k < 0 {
+			return 0, "", false
+		}
+
+		k += j
+		end := k + open
+		if end < len(s) && s[end] == '`' {
+			// longer backtick run is part of the code
+			j = end + len(s[end:]) - len(strings.TrimLeft(s[end:], "`"))
+			continue
+		}
+
+		code := strings.ReplaceAll(s[open:k], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+
+		return end, "<code>" + html.EscapeString(code) + "</code>", true
+	}
+
+	return 0, "", false
+}
+
+// Get the closing bracket and the closing parenthesis of the link
+// starting with the bracket at the position: [text](url "title").
+func (in *inline) linkBounds(i int) (int, int, bool) {
+	closing, ok := in.pairs[i]
+	if !ok || closing+1 >= len(in.s) || in.s[closing+1] != '(' {
+		return 0, 0, false
+	}
+
+	// parentheses inside the destination must be balanced
+	end, ok := in.pairs[closing+1]
+	if !ok {
+		return 0, 0, false
+	}
+
+	return closing, end, true
+}
+
+// Render link or image of the label and the destination with optional title.
+func link(label string, destination string, image bool, depth int) string {
+	destination, title := splitTitle(strings.TrimSpace(destination))
+	destination = strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+
+	href, ok := safeURL(destination, image)
+
+	var b strings.Builder
+	switch {
+	case image && ok:
+		fmt.Fprintf(&b, `<img src="%s" alt="%s"`, html.EscapeString(href), html.EscapeString(plainText(label)))
+		if title != "" {
+			fmt.Fprintf(&b, ` title="%s"`, html.EscapeString(title))
+		}
+		b.WriteString(" />")
+	case image:
+		b.WriteString(html.EscapeString(plainText(label)))
+	case ok:
+		fmt.Fprintf(&b, `<a href="%s"`, html.EscapeString(href))
+		if title != "" {
+			fmt.Fprintf(&b, ` title="%s"`, html.EscapeString(title))
+		}
+		fmt.Fprintf(&b, ` rel="nofollow noopener">%s</a>`, renderInline(label, depth+1))
+	default:
+		// the unsafe destination is dropped, only keeping the text
+		b.WriteString(renderInline(label, depth+1))
+	}
+
+	return b.String()
+}
+
+// Split the link destination and the optional quoted title.
+func splitTitle(s string) (string, string) {
+	k := strings.IndexAny(s, " \t\n")
+	if k < 0 {
+		return s, ""
+	}
+
+	title := strings.TrimSpace(s[k:])
+	if len(title) >= 2 && (title[0] == '"' || title[0] == '\'') && title[len(title)-1] == title[0] {
+		title = title[1 : len(title)-1]
+	}
+
+	return s[:k], title
+}
+
+// Render autolink, line break tag or allowed inline HTML starting with angle bracket.
+func (in *inline) angle(i int) (int, string, bool) {
+	s := in.s[i:]
+	if m := autolinkPattern.FindStringSubmatch(s); m != nil {
+		href, ok := safeURL(m[1], false)
+		if !ok {
+			return 0, "", false
+		}
+
+		return len(m[0]), fmt.Sprintf(`<a href="%s" rel="nofollow noopener">%s</a>`, html.EscapeString(href), html.EscapeString(m[1])), true
+	}
+
+	if m := autoEmailPattern.FindStringSubmatch(s); m != nil {
+		return len(m[0]), fmt.Sprintf(`<a href="mailto:%s">%s</a>`, html.EscapeString(m[1]), html.EscapeString(m[1])), true
+	}
+
+	if m := lineBreakTagPattern.FindString(s); m != "" {
+		return len(m), "<br />", true
+	}
+
+	m := inlineHTMLPattern.FindStringSubmatch(s)
+	if m == nil || !contains(ALLOWED_INLINE_HTML, m[1]) {
+		return 0, "", false
+	}
+
+	closing := "</" + m[1] + ">"
+	if in.hasFailed(closing, i) {
+		return 0, "", false
+	}
+
+	k := strings.Index(s[len(m[0]):], closing)
+	if k < 0 {
+		in.fail(closing, i)
+		return 0, "", false
+	}
+
+	inner := s[len(m[0]) : len(m[0])+k]
+
+	return len(m[0]) + k + len(closing), "<" + m[1] + ">" + renderInline(inner, in.depth+1) + closing, true
+}
+
+// Render emphasis at the position: **strong**, __strong__, *em*, _em_ or ~~del~~.
+// Opening delimiter must be followed by non space and closing delimiter
+// preceded by non space. Underscore inside a word is not a delimiter.
+// The delimiter without closing delimiter is remembered as failed, the later
+// same delimiters would only search the rest of the same text.
+func (in *inline) emphasis(i int) (int, string, bool) {
+	s := in.s
+	c := s[i]
+	rest := s[i:]
+
+	var delimiter, tag string
+	switch {
+	case c == '~' && strings.HasPrefix(rest, "~~"):
+		delimiter, tag = "~~", "del"
+	case c == '~':
+		return 0, "", false
+	case strings.HasPrefix(rest, string([]byte{c, c})):
+		delimiter, tag = string([]byte{c, c}), "strong"
+	default:
+		delimiter, tag = string(c), "em"
+	}
+
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return 0, "", false
+	}
+
+	start := len(delimiter)
+	if start >= len(rest) || rest[start] == ' ' || rest[start] == '\n' {
+		return 0, "", false
+	}
+
+	if in.hasFailed(delimiter, i) {
+		return 0, "", false
+	}
+
+	for j := start + 1; j <= len(rest)-len(delimiter); j++ {
+		if rest[j] == '\\' {
+			j++
+			continue
+		}
+
+		if rest[j] == '`' {
+			// code span content is not searched for the closing delimiter
+			if n, _, ok := in.codeSpan(i + j); ok {
+				j += n - 1
+				continue
+			}
+		}
+
+		if !strings.HasPrefix(rest[j:], delimiter) || rest[j-1] == ' ' || rest[j-1] == '\n' {
+			continue
+		}
+
+		end := j + len(delimiter)
+
+		// single delimiter must not be the part of double delimiter
+		if len(delimiter) == 1 && end < len(rest) && rest[end] == c {
+			j++
+			continue
+		}
+
+		if c == '_' && end < len(rest) && isWordByte(rest[end]) {
+			continue
+		}
+
+		return end, "<" + tag + ">" + renderInline(rest[start:j], in.depth+1) + "</" + tag + ">", true
+	}
+
+	in.fail(delimiter, i)
+
+	return 0, "", false
+}
+
+// Check the URL is allowed, returning the URL to be written.
+func safeURL(raw string, image bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.ContainsAny(raw, " \t\n\"'<>`") {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme == "":
+		return raw, true
+	case image:
+		return raw, scheme == "http" || scheme == "https"
+	default:
+		return raw, contains(ALLOWED_SCHEMES, scheme)
+	}
+}
+
+// Strip the inline syntax of the text used as attribute value.
+func plainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "~", "", "`", "", "[", "", "]", "").Replace(s)
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderInline(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "*em* and **strong**", want: "<p><em>em</em> and <strong>strong</strong></p>\n"},
+		{src: "_em_ and __strong__ and ~~del~~", want: "<p><em>em</em> and <strong>strong</strong> and <del>del</del></p>\n"},
+		{src: "snake_case_name", want: "<p>snake_case_name</p>\n"},
+		{src: "*unclosed and _closed_ *a", want: "<p>*unclosed and <em>closed</em> *a</p>\n"},
+		{src: "`*code*` *em*", want: "<p><code>*code*</code> <em>em</em></p>\n"},
+		{src: "``a ` b`` `unclosed", want: "<p><code>a ` b</code> `unclosed</p>\n"},
+		{src: "[link](https://example.com \"title\")", want: `<p><a href="https://example.com" title="title" rel="nofollow noopener">link</a></p>` + "\n"},
+		{src: "[a [nested] label](/path_(x))", want: `<p><a href="/path_(x)" rel="nofollow noopener">a [nested] label</a></p>` + "\n"},
+		{src: "[unsafe](javascript:alert(1))", want: "<p>unsafe</p>\n"},
+		{src: "![cover](https://example.com/a.jpg)", want: `<p><img src="https://example.com/a.jpg" alt="cover" /></p>` + "\n"},
+		{src: "[unclosed](https://example.com", want: "<p>[unclosed](https://example.com</p>\n"},
+		{src: "<u>*under*</u> <u>unclosed", want: "<p><u><em>under</em></u> &lt;u&gt;unclosed</p>\n"},
+		{src: "<script>alert(1)</script>", want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.src); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRenderNesting(t *testing.T) {
+	src := strings.Repeat("> ", MAX_NESTING+5) + "quote"
+	got := Render(src)
+	if n := strings.Count(got, "<blockquote>"); n != MAX_NESTING+1 {
+		t.Errorf("rendered %d blockquotes, want %d", n, MAX_NESTING+1)
+	}
+
+	if strings.Count(got, "<blockquote>") != strings.Count(got, "</blockquote>") {
+		t.Errorf("unbalanced blockquotes %q", got)
+	}
+
+	src = strings.Repeat("*a _b ", MAX_NESTING) + "c" + strings.Repeat(" b_ a*", MAX_NESTING)
+	got = Render(src)
+	if strings.Count(got, "<em>") != strings.Count(got, "</em>") {
+		t.Errorf("unbalanced emphasis %q", got)
+	}
+}
+
+// Unclosed and deeply nested delimiters must not make the rendering
+// quadratic, every body is rendered well under the time limit.
+func TestRenderAdversarialInput(t *testing.T) {
+	const size = 256 << 10
+
+	units := []string{
+		"*a ", "_a ", "**a ", "__a ", "~~a ", "`a ", "``a ", "*`a ",
+		"[a ", "[a](", "![a](", "(", "<u>a ", "*a _a ~~a **a ",
+		"> ", "- ", "1. ",
+	}
+
+	var sources []string
+	for _, unit := range units {
+		sources = append(sources, strings.Repeat(unit, size/len(unit)))
+	}
+
+	sources = append(sources,
+		strings.Repeat("*a _b ", size/12)+strings.Repeat(" b_ a*", size/12),
+		strings.Repeat("[", size/5)+strings.Repeat("](x)", size/5),
+		strings.Repeat("*a ", size/6)+"\n\n"+strings.Repeat("*a\n", size/6),
+	)
+
+	for _, src := range sources {
+		start := time.Now()
+		Render(src)
+
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("rendering %q... took %v", src[:12], elapsed)
+		}
+	}
+}
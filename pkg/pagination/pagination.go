@@ -0,0 +1,270 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+var (
+	DEFAULT_LIMIT uint64 = 20
+	MAX_LIMIT     uint64 = 100
+
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Pagination request from query string.
+// Using page for offset pagination, or cursor for keyset pagination.
+// When cursor is provided, page will be ignored.
+type Request struct {
+	Page   string
+	Limit  string
+	Cursor string
+}
+
+// Create pagination request from URL query string.
+func NewRequest(q url.Values) Request {
+	return Request{
+		Page:   q.Get("page"),
+		Limit:  q.Get("limit"),
+		Cursor: q.Get("cursor"),
+	}
+}
+
+func (r *Request) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Page, is.Digit, validation.Length(1, 9)),
+		validation.Field(&r.Limit, is.Digit, validation.Length(1, 3)),
+		validation.Field(&r.Cursor, validation.Length(0, 255)),
+	)
+}
+
+// Parse the request into page.
+func (r *Request) Parse() (*Page, error) {
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	p := Page{
+		Number: 1,
+		Limit:  DEFAULT_LIMIT,
+	}
+
+	if r.Page != "" {
+		p.Number, _ = strconv.ParseUint(r.Page, 10, 64)
+		if p.Number < 1 {
+			p.Number = 1
+		}
+	}
+
+	if r.Limit != "" {
+		p.Limit, _ = strconv.ParseUint(r.Limit, 10, 64)
+		if p.Limit < 1 {
+			p.Limit = 1
+		}
+
+		if p.Limit > MAX_LIMIT {
+			p.Limit = MAX_LIMIT
+		}
+	}
+
+	if r.Cursor != "" {
+		c, err := DecodeCursor(r.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Number = 0
+		p.Cursor = c
+	}
+
+	return &p, nil
+}
+
+// Keyset cursor. The value is the sorted column value and the ID
+// is the tie breaker, so rows with same value are not skipped.
+type Cursor struct {
+	Value    uint64
+	ID       uint64
+	Backward bool
+}
+
+// Encode cursor into opaque string.
+func (c Cursor) Encode() string {
+	direction := "n"
+	if c.Backward {
+		direction = "p"
+	}
+
+	raw := fmt.Sprintf("%s:%d:%d", direction, c.Value, c.ID)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode opaque string into cursor.
+// If the string is not a valid cursor, throwing an err invalid cursor.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || (parts[0] != "n" && parts[0] != "p") {
+		return nil, ErrInvalidCursor
+	}
+
+	value, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{
+		Value:    value,
+		ID:       id,
+		Backward: parts[0] == "p",
+	}, nil
+}
+
+// Sort order used by keyset pagination.
+// Value and ID are the column names, they must never come from user input.
+type Order struct {
+	Value string
+	ID    string
+	Desc  bool
+}
+
+// Parsed pagination request.
+type Page struct {
+	Number uint64
+	Limit  uint64
+	Cursor *Cursor
+}
+
+// Get the keyset condition and its arguments.
+// When the page is not using cursor the condition always true.
+func (p *Page) Keyset(o Order) (string, []any) {
+	if p.Cursor == nil {
+		return "1 = 1", nil
+	}
+
+	op := ">"
+	if o.Desc != p.Cursor.Backward {
+		op = "<"
+	}
+
+	return fmt.Sprintf("(%s, %s) %s (?, ?)", o.Value, o.ID, op), []any{p.Cursor.Value, p.Cursor.ID}
+}
+
+// Get the ORDER BY expression. Backward cursor is reading the rows in
+// reverse order, the rows are reversed back on Trim.
+func (p *Page) OrderBy(o Order) string {
+	direction := "ASC"
+	if o.Desc != p.IsBackward() {
+		direction = "DESC"
+	}
+
+	return fmt.Sprintf("%s %s, %s %s", o.Value, direction, o.ID, direction)
+}
+
+// Get how many rows need to be fetched.
+// One extra row is fetched to know if there is more rows.
+func (p *Page) Fetch() uint64 {
+	return p.Limit + 1
+}
+
+// Get the offset, always zero when using cursor.
+func (p *Page) Offset() uint64 {
+	if p.Cursor != nil || p.Number < 1 {
+		return 0
+	}
+
+	return (p.Number - 1) * p.Limit
+}
+
+// Checking the page is reading backward from cursor.
+func (p *Page) IsBackward() bool {
+	return p.Cursor != nil && p.Cursor.Backward
+}
+
+// Pagination meta on API response.
+type Meta struct {
+	Page       uint64 `json:"page,omitempty"`
+	Limit      uint64 `json:"limit"`
+	Total      uint64 `json:"total"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// Create pagination meta. The cursors are the cursor of every returned
+// row in the order they are returned.
+func NewMeta(p Page, total uint64, hasMore bool, cursors []Cursor) *Meta {
+	m := &Meta{
+		Page:  p.Number,
+		Limit: p.Limit,
+		Total: total,
+	}
+
+	if len(cursors) == 0 {
+		return m
+	}
+
+	first := cursors[0]
+	first.Backward = true
+	last := cursors[len(cursors)-1]
+	last.Backward = false
+
+	switch {
+	case p.Cursor == nil:
+		if hasMore {
+			m.NextCursor = last.Encode()
+		}
+
+		if p.Number > 1 {
+			m.PrevCursor = first.Encode()
+		}
+	case p.Cursor.Backward:
+		m.NextCursor = last.Encode()
+
+		if hasMore {
+			m.PrevCursor = first.Encode()
+		}
+	default:
+		m.PrevCursor = first.Encode()
+
+		if hasMore {
+			m.NextCursor = last.Encode()
+		}
+	}
+
+	return m
+}
+
+// Trim the extra fetched row and restore the order of backward page.
+// Returning the rows and whether there is more rows.
+func Trim[T any](p Page, rows []T) ([]T, bool) {
+	hasMore := uint64(len(rows)) > p.Limit
+	if hasMore {
+		rows = rows[:p.Limit]
+	}
+
+	if p.IsBackward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	return rows, hasMore
+}
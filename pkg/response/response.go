@@ -10,6 +10,7 @@ type Response struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    any    `json:"data"`
+	Meta    any    `json:"meta,omitempty"`
 }
 
 // Set the API code response. Should always call JSON() function.
@@ -30,6 +31,12 @@ func (r *Response) SetData(data any) *Response {
 	return r
 }
 
+// Set the API meta response, for example pagination. Should always call JSON() function.
+func (r *Response) SetMeta(meta any) *Response {
+	r.Meta = meta
+	return r
+}
+
 // Render the JSON response. Always call this function after set up
 // code, message, data.
 func (r *Response) JSON(w http.ResponseWriter) {
@@ -37,11 +44,25 @@ func (r *Response) JSON(w http.ResponseWriter) {
 	w.WriteHeader(r.Code)
 
 	err := json.NewEncoder(w).Encode(r)
+
+	// the response is reused by the handler, meta must not leak
+	// into the next response that does not set it
+	r.Meta = nil
+
 	if err != nil {
 		panic(err)
 	}
 }
 
+// Redirect permanently to the path, keeping the query string of the request.
+func Redirect(w http.ResponseWriter, r *http.Request, path string) {
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+
+	http.Redirect(w, r, path, http.StatusMovedPermanently)
+}
+
 func (r *Response) Error(err error) *Response {
 	return &Response{
 		Message: err.Error(),
@@ -0,0 +1,116 @@
+package slug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Maximum length of the generated slug, without the numeric suffix.
+var MAX_LENGTH = 100
+
+// Maximum attempts saving the generated slug. The slug taken concurrently
+// since it was generated is retried with the next available slug.
+var MAX_ATTEMPTS = 3
+
+// Convert the text into URL safe slug. Letters are transliterated into
+// ASCII, apostrophes are removed and anything else than letter and digit
+// is replaced with single dash. Letters without transliteration, like
+// CJK or emoji, are stripped, so the slug may be empty.
+func Make(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || unicode.Is(unicode.Mn, r):
+			// dropped without separating the word
+		default:
+			t, ok := transliterations[r]
+			if !ok {
+				t = "-"
+			}
+
+			b.WriteString(t)
+		}
+	}
+
+	words := strings.FieldsFunc(b.String(), func(r rune) bool {
+		return r == '-'
+	})
+
+	slug := strings.Join(words, "-")
+	if len(slug) > MAX_LENGTH {
+		slug = strings.Trim(slug[:MAX_LENGTH], "-")
+	}
+
+	return slug
+}
+
+// Get the slug that is not taken yet. The base is used when available,
+// otherwise suffixed with the lowest available number starting from 2.
+func Unique(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+
+	if !used[base] {
+		return base
+	}
+
+	for n := 2; ; n++ {
+		slug := fmt.Sprintf("%s-%d", base, n)
+		if !used[slug] {
+			return slug
+		}
+	}
+}
+
+// Checking the slug is the base itself or the base with numeric suffix.
+func HasBase(slug string, base string) bool {
+	if slug == base {
+		return true
+	}
+
+	suffix := strings.TrimPrefix(slug, base+"-")
+	if suffix == slug {
+		return false
+	}
+
+	_, err := strconv.ParseUint(suffix, 10, 64)
+
+	return err == nil
+}
+
+// Lowercase letters transliterated into ASCII, covering the Latin,
+// Greek and Cyrillic alphabets.
+var transliterations = map[rune]string{
+	// Latin
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g", 'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĳ': "ij", 'ĵ': "j", 'ķ': "k", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n", 'ŉ': "n", 'ŋ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'œ': "oe", 'ŕ': "r", 'ŗ': "r", 'ř': "r", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ș': "s",
+	'ß': "ss", 'ţ': "t", 'ť': "t", 'ŧ': "t", 'ț': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w", 'ý': "y", 'ÿ': "y", 'ŷ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+
+	// Greek
+	'α': "a", 'ά': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'έ': "e", 'ζ': "z", 'η': "i", 'ή': "i",
+	'θ': "th", 'ι': "i", 'ί': "i", 'ϊ': "i", 'ΐ': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'ό': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'ύ': "y", 'ϋ': "y",
+	'ΰ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o", 'ώ': "o",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "yo", 'є': "ye",
+	'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh",
+	'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
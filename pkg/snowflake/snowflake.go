@@ -0,0 +1,99 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ID layout from the most significant bit, the sign bit is always zero:
+// 41 bits of milliseconds since the epoch, 10 bits of node ID
+// and 12 bits of sequence within the same millisecond.
+const (
+	NODE_BITS     = 10
+	SEQUENCE_BITS = 12
+
+	MAX_NODE     = 1<<NODE_BITS - 1
+	MAX_SEQUENCE = 1<<SEQUENCE_BITS - 1
+)
+
+var (
+	// Custom epoch 2022-01-01 00:00:00 UTC in milliseconds,
+	// keeping the IDs small and valid for about 69 years.
+	EPOCH int64 = 1640995200000
+
+	ErrInvalidNode = errors.New("node ID must be between 0 and 1023")
+)
+
+// Generator of time ordered unique 64-bit IDs.
+// Every running server must use different node ID.
+// Safe to use from multiple goroutines.
+type Generator struct {
+	mu       sync.Mutex
+	node     int64
+	last     int64
+	sequence int64
+}
+
+// Generate the next ID. When the sequence of the current millisecond
+// is exhausted, waiting for the next millisecond. When the clock moved
+// backwards, the last timestamp is kept until the clock catches up.
+func (g *Generator) Generate() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := timestamp()
+	if now < g.last {
+		now = g.last
+	}
+
+	if now == g.last {
+		g.sequence = (g.sequence + 1) & MAX_SEQUENCE
+		if g.sequence == 0 {
+			for now <= g.last {
+				time.Sleep(100 * time.Microsecond)
+				now = timestamp()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+
+	g.last = now
+
+	return uint64(now<<(NODE_BITS+SEQUENCE_BITS) | g.node<<SEQUENCE_BITS | g.sequence)
+}
+
+// Milliseconds since the epoch.
+func timestamp() int64 {
+	return time.Now().UnixMilli() - EPOCH
+}
+
+// Create generator with the node ID.
+// If the node ID out of range, throwing an err invalid node.
+func New(node int64) (*Generator, error) {
+	if node < 0 || node > MAX_NODE {
+		return nil, ErrInvalidNode
+	}
+
+	return &Generator{node: node, last: -1}, nil
+}
+
+var defaultGenerator, _ = New(0)
+
+// Set node ID of the default generator, must be called before generating any ID.
+func SetNode(node int64) error {
+	g, err := New(node)
+	if err != nil {
+		return err
+	}
+
+	defaultGenerator = g
+
+	return nil
+}
+
+// Generate the next ID using the default generator.
+func Generate() uint64 {
+	return defaultGenerator.Generate()
+}
@@ -0,0 +1,89 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGenerateUniqueFromManyGoroutines(t *testing.T) {
+	const goroutines = 64
+	const perGoroutine = 5000
+
+	g, err := New(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([][]uint64, goroutines)
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			generated := make([]uint64, perGoroutine)
+			for j := range generated {
+				generated[j] = g.Generate()
+			}
+
+			ids[i] = generated
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[uint64]struct{}, goroutines*perGoroutine)
+	for i, generated := range ids {
+		for j, id := range generated {
+			if j > 0 && id <= generated[j-1] {
+				t.Fatalf("goroutine %d: id %d is not greater than previous id %d", i, id, generated[j-1])
+			}
+
+			if _, ok := seen[id]; ok {
+				t.Fatalf("goroutine %d: duplicate id %d", i, id)
+			}
+			seen[id] = struct{}{}
+
+			if node := id >> SEQUENCE_BITS & MAX_NODE; node != 7 {
+				t.Fatalf("id %d has node %d, want 7", id, node)
+			}
+		}
+	}
+}
+
+func TestNewNodeBounds(t *testing.T) {
+	for _, node := range []int64{-1, MAX_NODE + 1} {
+		_, err := New(node)
+		if !errors.Is(err, ErrInvalidNode) {
+			t.Errorf("New(%d) error = %v, want %v", node, err, ErrInvalidNode)
+		}
+	}
+
+	for _, node := range []int64{0, MAX_NODE} {
+		_, err := New(node)
+		if err != nil {
+			t.Errorf("New(%d) error = %v, want nil", node, err)
+		}
+	}
+}
+
+func TestSetNodeBounds(t *testing.T) {
+	defer SetNode(0)
+
+	for _, node := range []int64{-1, 1024} {
+		err := SetNode(node)
+		if !errors.Is(err, ErrInvalidNode) {
+			t.Errorf("SetNode(%d) error = %v, want %v", node, err, ErrInvalidNode)
+		}
+	}
+
+	err := SetNode(MAX_NODE)
+	if err != nil {
+		t.Fatalf("SetNode(%d) error = %v, want nil", MAX_NODE, err)
+	}
+
+	if node := Generate() >> SEQUENCE_BITS & MAX_NODE; node != MAX_NODE {
+		t.Errorf("generated node = %d, want %d", node, MAX_NODE)
+	}
+}
@@ -0,0 +1,79 @@
+package textmetrics
+
+import (
+	"math"
+	"unicode"
+)
+
+var (
+	// Average silent reading speed of text written with spaces between words.
+	WORDS_PER_MINUTE float64 = 230
+
+	// Average silent reading speed of Chinese and Japanese characters.
+	CHARACTERS_PER_MINUTE float64 = 500
+)
+
+// Metrics of a text. Words are separated by spaces or punctuation,
+// Chinese and Japanese characters are written without spaces, so they
+// are counted per character instead.
+type Metrics struct {
+	Words      uint64
+	Characters uint64
+}
+
+// Analyze the text. A word is a run of letters, digits and marks, joined
+// by an apostrophe or a hyphen between them, like "don't" or "well-known".
+// Number with decimal or thousands separator, like "3.5", is single word.
+func Analyze(text string) Metrics {
+	var m Metrics
+	var inWord, joiner, digit bool
+
+	for _, r := range text {
+		switch {
+		case isCharacterScript(r):
+			m.Characters++
+			inWord, joiner = false, false
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if !inWord {
+				m.Words++
+			}
+
+			inWord, joiner, digit = true, false, unicode.IsDigit(r)
+		case inWord && !joiner && (isJoiner(r) || digit && (r == '.' || r == ',')):
+			// the word continues only when followed by a letter or digit again,
+			// a trailing joiner just ends the word
+			joiner = true
+		default:
+			inWord, joiner = false, false
+		}
+	}
+
+	return m
+}
+
+// Counting words with every Chinese and Japanese character as a word.
+func (m Metrics) WordCounts() uint64 {
+	return m.Words + m.Characters
+}
+
+// Estimating reading time in seconds, rounded up.
+func (m Metrics) ReadingTime() uint64 {
+	minutes := float64(m.Words)/WORDS_PER_MINUTE + float64(m.Characters)/CHARACTERS_PER_MINUTE
+
+	return uint64(math.Ceil(minutes * 60))
+}
+
+// Checking the rune belongs to a script written without spaces between words.
+func isCharacterScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Bopomofo)
+}
+
+// Checking the rune may join two parts of a word.
+func isJoiner(r rune) bool {
+	switch r {
+	case '\'', '’', '-', '‐':
+		return true
+	}
+
+	return false
+}
@@ -1,6 +1,9 @@
 package time
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 // Generate current time to unix timestamp.
 func CurrentTimeToUnixTimestamp() uint64 {
@@ -15,3 +18,24 @@ func UnixToTime(n uint64) time.Time {
 
 	return time.Unix(t, 0)
 }
+
+// Parse the formatted time string into unix timestamp.
+func ParseToUnixTimestamp(layout string, value string) (uint64, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(t.UnixMilli()), nil
+}
+
+// Format nullable unix timestamp to time format, nil when the timestamp is null.
+func NullUnixToTime(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+
+	t := UnixToTime(uint64(n.Int64))
+
+	return &t
+}